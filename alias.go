@@ -0,0 +1,49 @@
+// alias.go
+package paramvalidator
+
+// WithAliasRename makes filtering rewrite aliases such as q in "query|q=[*]" to primary parameter name
+func WithAliasRename(enabled bool) Option {
+	return func(pv *ParamValidator) {
+		pv.renameAliases = enabled
+	}
+}
+
+// addParamAliases makes aliases of rule share index of its primary name
+func (rs *ruleSnapshot) addParamAliases(rule *ParamRule, index int) {
+	for _, alias := range rule.Aliases {
+		rs.paramIndex.AddAlias(alias, index)
+	}
+}
+
+// paramNames returns primary name of rule followed by its aliases
+func (rule *ParamRule) paramNames() []string {
+	if len(rule.Aliases) == 0 {
+		return []string{rule.Name}
+	}
+	return append([]string{rule.Name}, rule.Aliases...)
+}
+
+// rewrittenKey returns declared name filtering writes instead of segment key, "" when key is kept
+// Aliases are renamed with WithAliasRename, keys differing only in case with CaseRewriteKeys
+func rewrittenKey[T ~string | ~[]byte](rs *ruleSnapshot, segment T, rule *ParamRule) string {
+	if !rs.renameAliases && rs.caseFolding&CaseRewriteKeys == 0 {
+		return ""
+	}
+	if rule.Name == PatternAll || isParamNamePattern(rule.Name) {
+		return ""
+	}
+	if segmentKeyEquals(segment, rule.Name, rs.decodingMode, false) {
+		return ""
+	}
+
+	if !segmentKeyEquals(segment, rule.Name, rs.decodingMode, rs.foldsKey(rule)) {
+		if rs.renameAliases {
+			return rule.Name
+		}
+		return ""
+	}
+	if rs.caseFolding&CaseRewriteKeys != 0 {
+		return rule.Name
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+const aliasRules = "pg|p=[range:1..100]; /search?query|q|search=[len:1..20]&sort=[name,date]"
+
+var aliasPlugins = WithPlugins(plugins.NewRangePlugin(), plugins.NewLengthPlugin())
+
+func TestAliasValidate(t *testing.T) {
+	pv := newTestValidator(t, aliasRules, aliasPlugins)
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"query=shoes", true},
+		{"q=shoes", true},
+		{"search=shoes&pg=2", true},
+		{"q=this+query+is+way+too+long", false},
+		{"p=5", true},
+		{"p=500", false},
+		{"Q=shoes", false},
+		{"qq=shoes", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL("/search?" + tt.query); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQuery("/search", tt.query); result != tt.expected {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQueryBytes([]byte("/search"), []byte(tt.query)); result != tt.expected {
+			t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateURLDetailed("/search?" + tt.query); result.Valid != tt.expected {
+			t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.query, result.Valid, tt.expected)
+		}
+	}
+}
+
+func TestAliasSharesIndex(t *testing.T) {
+	pv := newTestValidator(t, "/search?query|q=[*]@first&+page|pg=[*]; requires query->page")
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/search?q=a&pg=1", true},
+		{"/search?q=a", false},
+		{"/search?query=a&q=b&page=1", true},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+
+	if result := pv.FilterURL("/search?q=a&query=b&pg=1"); result != "/search?q=a&pg=1" {
+		t.Errorf("FilterURL = %q, expected only first of query and its alias kept", result)
+	}
+}
+
+func TestAliasFilterRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		query    string
+		expected string
+	}{
+		{"aliases kept", nil, "q=shoes&p=2&x=1", "q=shoes&p=2"},
+		{"aliases renamed", []Option{WithAliasRename(true)}, "q=shoes&p=2&x=1", "query=shoes&pg=2"},
+		{"primary untouched", []Option{WithAliasRename(true)}, "query=shoes&pg=2", "query=shoes&pg=2"},
+		{"renamed with folding", []Option{WithAliasRename(true), WithCaseFolding(CaseFoldKeys | CaseRewriteKeys)}, "Q=shoes&PG=2", "query=shoes&pg=2"},
+		{"renamed with canonical order", []Option{WithAliasRename(true), WithCanonicalMode(CanonicalByName)}, "sort=name&search=shoes", "query=shoes&sort=name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, aliasRules, append([]Option{aliasPlugins}, tt.options...)...)
+
+			if result := pv.FilterQuery("/search", tt.query); result != tt.expected {
+				t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.expected)
+			}
+			buffer := make([]byte, 0, 4*len(tt.query))
+			if result := pv.FilterQueryBytes([]byte("/search"), []byte(tt.query), buffer); string(result) != tt.expected {
+				t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAliasRenameFilterQueryBytesBuffer(t *testing.T) {
+	pv := newTestValidator(t, "/search?query|q=[*]&pg|p=[*]&sort=[name,date]", WithAliasRename(true))
+
+	// Renamed keys are longer than aliases they replace
+	tests := []struct {
+		query    string
+		capacity int
+		expected string
+	}{
+		{"q=shoes&p=2", 11, ""},
+		{"q=shoes&p=2", 15, ""},
+		{"q=shoes&p=2", 16, "query=shoes&pg=2"},
+		{"p=2&sort=name", 13, ""},
+		{"p=2&sort=name", 14, "pg=2&sort=name"},
+	}
+
+	for _, tt := range tests {
+		buffer := make([]byte, 0, tt.capacity)
+		urlPath, query := []byte("/search"), []byte(tt.query)
+		result := pv.FilterQueryBytes(urlPath, query, buffer)
+		if string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q, cap %d) = %q, expected %q", tt.query, tt.capacity, result, tt.expected)
+			continue
+		}
+		if tt.expected == "" {
+			continue
+		}
+		if !usesBuffer(result, buffer) {
+			t.Errorf("FilterQueryBytes(%q) result does not share caller's buffer", tt.query)
+		}
+		allocs := testing.AllocsPerRun(100, func() {
+			pv.FilterQueryBytes(urlPath, query, buffer)
+		})
+		if allocs != 0 {
+			t.Errorf("FilterQueryBytes(%q) allocated %v times, expected 0", tt.query, allocs)
+		}
+	}
+}
+
+func TestAliasDuplicates(t *testing.T) {
+	tests := []struct {
+		query    string
+		valid    bool
+		filtered string
+		renamed  string
+	}{
+		{"q=a&query=b", true, "query=b", "query=b"},
+		{"query=a&q=b&q=c", true, "q=c", "query=c"},
+		{"p=1", true, "p=1", "page=1"},
+		{"p=1&page=1", false, "", ""},
+		{"page=1&p=3", false, "", ""},
+		{"t=a&tag=b", true, "t=a&tag=b", "tag=a&tag=b"},
+		{"t=a&tag=b&t=c", false, "t=a&tag=b", "tag=a&tag=b"},
+	}
+
+	// Occurrences under alias and primary name count together
+	rules := "/search?query|q=[*]@last&page|p=[1,2]@reject&tag|t=[*]{0,2}&sort=[name,date]"
+	pv := newTestValidator(t, rules)
+	renaming := newTestValidator(t, rules, WithAliasRename(true))
+	for _, tt := range tests {
+		if result := pv.ValidateQuery("/search", tt.query); result != tt.valid {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.valid)
+		}
+		if result := pv.FilterQuery("/search", tt.query); result != tt.filtered {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.filtered)
+		}
+		buffer := make([]byte, 0, 2*len(tt.query))
+		if result := renaming.FilterQueryBytes([]byte("/search"), []byte(tt.query), buffer); string(result) != tt.renamed {
+			t.Errorf("FilterQueryBytes(%q) with rename = %q, expected %q", tt.query, result, tt.renamed)
+		}
+	}
+}
+
+func TestAliasConditions(t *testing.T) {
+	pv := newTestValidator(t, "/search?type|t=[image,video]&q=[*]; variant(type=image) width=[*]; "+
+		"/catalog?category|cat|c=[shoes,books]&sub|s=[*]; when(category=shoes) sub=[sneakers,boots]")
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/search?t=image&width=10", true},
+		{"/search?t=video&width=10", false},
+		{"/catalog?c=shoes&s=boots", true},
+		{"/catalog?c=shoes&s=hats", false},
+		{"/catalog?cat=books&s=hats", true},
+		{"/catalog?category=shoes&s=hats", false},
+		{"/catalog?c=shoes&sub=hats", false},
+		{"/catalog?c=books&category=shoes&s=hats", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+
+	// Violation is reported under name used in URL
+	result := pv.ValidateURLDetailed("/catalog?c=shoes&s=hats")
+	if len(result.Violations) != 1 || result.Violations[0].Param != "s" || result.Violations[0].Kind != ViolationCondition {
+		t.Errorf("Violations = %+v, expected condition mismatch of s", result.Violations)
+	}
+
+	renaming := newTestValidator(t, "/catalog?category|cat|c=[shoes,books]&sub|s=[*]; when(category=shoes) sub=[sneakers,boots]",
+		WithAliasRename(true))
+	if result := renaming.FilterURL("/catalog?c=shoes&s=hats&cat=books"); result != "/catalog?category=shoes&category=books" {
+		t.Errorf("FilterURL = %q, expected conditioned alias dropped and kept aliases renamed", result)
+	}
+}
@@ -0,0 +1,143 @@
+// assert.go
+package paramvalidator
+
+import "bytes"
+
+// assertOperators lists comparison operators of assert clause, longest first
+var assertOperators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// compiledAssertion is assertion resolved to parameter indices
+type compiledAssertion struct {
+	left            int
+	right           int
+	leftTransforms  []ValueTransform
+	rightTransforms []ValueTransform
+	assertion       Assertion
+}
+
+// segmentSpan holds position of query segment, end is 0 when nothing was recorded
+type segmentSpan struct {
+	start uint16
+	end   uint16
+}
+
+// String returns assertion in rule syntax
+func (a Assertion) String() string {
+	return "assert(" + a.Left + " " + a.Operator + " " + a.Right + ")"
+}
+
+// assertionHolds checks assertion against values of accepted segments
+// Each operand is its first accepted occurrence, which under @first and @last is the occurrence kept
+// Assertion holds trivially when either parameter is absent
+func (rs *ruleSnapshot) assertionHolds(qt *queryTracker, assertion *compiledAssertion) bool {
+	if !qt.present.GetBit(assertion.left) || !qt.present.GetBit(assertion.right) {
+		return true
+	}
+	leftSpan, rightSpan := qt.state.spans[assertion.left], qt.state.spans[assertion.right]
+	if leftSpan.end == 0 || rightSpan.end == 0 {
+		return true
+	}
+
+	var leftBuf, rightBuf, leftTransformBuf, rightTransformBuf [64]byte
+	left := transformBytes(leftTransformBuf[:0], rs.spanValue(qt, leftSpan, leftBuf[:0]), assertion.leftTransforms)
+	right := transformBytes(rightTransformBuf[:0], rs.spanValue(qt, rightSpan, rightBuf[:0]), assertion.rightTransforms)
+	return operatorHolds(assertion.assertion.Operator, compareParamValues(left, right))
+}
+
+// spanValue appends decoded value of recorded segment to dst
+func (rs *ruleSnapshot) spanValue(qt *queryTracker, span segmentSpan, dst []byte) []byte {
+	if qt.queryBytes != nil {
+		return appendSegmentValue(dst, qt.queryBytes[span.start:span.end], rs.decodingMode)
+	}
+	return appendSegmentValue(dst, qt.query[span.start:span.end], rs.decodingMode)
+}
+
+// appendSegmentValue appends value part of query segment to dst, decoding it when needed
+func appendSegmentValue[T ~string | ~[]byte](dst []byte, segment T, mode DecodingMode) []byte {
+	for i := 0; i < len(segment); i++ {
+		if segment[i] != '=' {
+			continue
+		}
+
+		value := segment[i+1:]
+		if needsDecoding(mode, value) {
+			if decoded, ok := appendDecoded(dst, value, mode); ok {
+				return decoded
+			}
+		}
+		return append(dst, value...)
+	}
+	return dst
+}
+
+// compareParamValues compares values numerically when both are decimal numbers, bytewise otherwise
+// Bytewise order keeps ISO 8601 dates comparable
+func compareParamValues(left, right []byte) int {
+	leftNum, leftOk := parseDecimal(left)
+	rightNum, rightOk := parseDecimal(right)
+	if !leftOk || !rightOk {
+		return bytes.Compare(left, right)
+	}
+
+	switch {
+	case leftNum < rightNum:
+		return -1
+	case leftNum > rightNum:
+		return 1
+	}
+	return 0
+}
+
+// parseDecimal parses optionally signed decimal number such as -12.50
+func parseDecimal(value []byte) (float64, bool) {
+	i := 0
+	negative := false
+	if i < len(value) && (value[i] == '-' || value[i] == '+') {
+		negative = value[i] == '-'
+		i++
+	}
+
+	var result float64
+	digits := 0
+	for ; i < len(value) && value[i] >= '0' && value[i] <= '9'; i++ {
+		result = result*10 + float64(value[i]-'0')
+		digits++
+	}
+
+	if i < len(value) && value[i] == '.' {
+		i++
+		scale := 0.1
+		for ; i < len(value) && value[i] >= '0' && value[i] <= '9'; i++ {
+			result += float64(value[i]-'0') * scale
+			scale /= 10
+			digits++
+		}
+	}
+
+	if digits == 0 || i != len(value) {
+		return 0, false
+	}
+	if negative {
+		result = -result
+	}
+	return result, true
+}
+
+// operatorHolds checks comparison result against assertion operator
+func operatorHolds(operator string, cmp int) bool {
+	switch operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestAssertionClauses(t *testing.T) {
+	rules := "/products?min_price=[cmp:>=0]&max_price=[cmp:>=0]&from=[*]&to=[*]; " +
+		"assert(min_price <= max_price); assert(from < to)"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"ordered prices", "/products?min_price=10&max_price=100", true},
+		{"equal prices", "/products?min_price=50&max_price=50", true},
+		{"numeric not lexical order", "/products?min_price=9&max_price=10", true},
+		{"reversed prices", "/products?max_price=10&min_price=100", false},
+		{"single operand", "/products?min_price=100", true},
+		{"operand rejected by plugin", "/products?min_price=100&max_price=-1", false},
+		{"ordered dates", "/products?from=2024-01-01&to=2024-02-01", true},
+		{"reversed dates", "/products?from=2024-03-01&to=2024-02-01", false},
+		{"equal dates", "/products?from=2024-03-01&to=2024-03-01", false},
+		{"no params", "/products", true},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewComparisonPlugin()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := ""
+			if i := strings.Index(tt.url, "?"); i != -1 {
+				query = tt.url[i+1:]
+			}
+			if result := pv.ValidateQuery("/products", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/products"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAssertionDuplicates(t *testing.T) {
+	rules := "/products?min_price=[cmp:>=0]@first&max_price=[cmp:>=0]@last; assert(min_price <= max_price)"
+
+	tests := []struct {
+		query    string
+		expected bool
+		filtered string
+	}{
+		{"min_price=10&min_price=500&max_price=100", true, "min_price=10&max_price=100"},
+		{"min_price=500&min_price=10&max_price=100", false, "min_price=500"},
+		{"max_price=5&max_price=100&min_price=10", true, "max_price=100&min_price=10"},
+		{"max_price=100&max_price=5&min_price=10", false, "min_price=10"},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewComparisonPlugin()))
+
+	for _, tt := range tests {
+		// Assertion compares occurrences kept by duplicate policy
+		if result := pv.ValidateQuery("/products", tt.query); result != tt.expected {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		filtered := pv.FilterQuery("/products", tt.query)
+		if filtered != tt.filtered {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, filtered, tt.filtered)
+		}
+		if !pv.ValidateQuery("/products", filtered) {
+			t.Errorf("Filtered query %q of %q is invalid", filtered, tt.query)
+		}
+	}
+}
+
+func TestAssertionOperators(t *testing.T) {
+	tests := []struct {
+		operator string
+		left     string
+		right    string
+		expected bool
+	}{
+		{"<", "1", "2", true},
+		{"<", "2", "2", false},
+		{">", "2.5", "2.25", true},
+		{">=", "-1", "-1", true},
+		{"==", "1.0", "1", true},
+		{"!=", "a", "a", false},
+		{"!=", "a", "b", true},
+		{"<", "abc", "abd", true},
+		{"<", "10", "9a", true},
+	}
+
+	for _, tt := range tests {
+		pv := newTestValidator(t, "a=[*]&b=[*]; assert(a "+tt.operator+" b)")
+		url := "/x?a=" + tt.left + "&b=" + tt.right
+		if result := pv.ValidateURL(url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) with %s = %v, expected %v", url, tt.operator, result, tt.expected)
+		}
+	}
+}
+
+func TestAssertionDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/products?min_price=[*]&max_price=[*]; assert(min_price <= max_price)",
+		WithDecoding(DecodeQuery))
+
+	result := pv.ValidateURLDetailed("/products?min_price=%35%30&max_price=10")
+	if len(result.Violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %+v", result.Violations)
+	}
+
+	violation := result.Violations[0]
+	if violation.Kind != ViolationAssertion || violation.Param != "min_price" || violation.Value != "50,10" ||
+		violation.Clause != "assert(min_price <= max_price)" || violation.URLPattern != "/products" {
+		t.Errorf("Unexpected assertion violation: %+v", violation)
+	}
+}
+
+func TestAssertionFilter(t *testing.T) {
+	pv := newTestValidator(t, "/products?min_price=[*]&max_price=[*]&page=[*]&sort=[*]; "+
+		"assert(min_price <= max_price); requires sort->max_price")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/products?min_price=1&max_price=5&page=2", "/products?min_price=1&max_price=5&page=2"},
+		{"/products?max_price=5&page=2&min_price=10", "/products?page=2&min_price=10"},
+		{"/products?min_price=10&max_price=5&sort=price", "/products?min_price=10"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	query := []byte("min_price=10&page=1&max_price=5")
+	buffer := make([]byte, 0, len(query))
+	if result := pv.FilterQueryBytes([]byte("/products"), query, buffer); string(result) != "min_price=10&page=1" {
+		t.Errorf("FilterQueryBytes = %q", result)
+	}
+}
@@ -0,0 +1,250 @@
+// canonical.go
+package paramvalidator
+
+import (
+	"slices"
+)
+
+// CanonicalMode controls ordering and encoding of filtered query parameters
+type CanonicalMode int
+
+const (
+	// CanonicalOff keeps parameters in client order (default)
+	CanonicalOff CanonicalMode = iota
+	// CanonicalByName sorts parameters by decoded name
+	CanonicalByName
+	// CanonicalByRule sorts parameters by declaration order, specific URL rule first and globals last
+	CanonicalByRule
+)
+
+// WithCanonicalMode makes filtering emit parameters in canonical order with exact duplicates collapsed
+// Keys and values are re-encoded as well, with decoding enabled only unreserved characters stay literal,
+// without it rules matched raw bytes, so only bytes URL standard escapes in query are encoded
+// FilterQueryBytes then also needs room for canonical form after filtered parameters
+func WithCanonicalMode(mode CanonicalMode) Option {
+	return func(pv *ParamValidator) {
+		pv.canonicalMode = mode
+	}
+}
+
+// canonicalStackSpans is number of query segments canonical ordering sorts without allocating
+const canonicalStackSpans = 32
+
+// canonicalSpan is query segment together with its sort key
+type canonicalSpan struct {
+	start    uint16
+	keyEnd   uint16
+	end      uint16
+	group    uint8
+	position int32
+}
+
+// appendCanonicalQuery appends query to dst in canonical form
+// Reports false when query has more segments than MaxParamValues
+func (rs *ruleSnapshot) appendCanonicalQuery(dst, query []byte, masks ParamMasks, urlPath string, scratch []byte) ([]byte, bool) {
+	// Typical queries sort on stack, longer ones grow spans on heap
+	var spanBuf [canonicalStackSpans]canonicalSpan
+	spans := spanBuf[:0]
+	active := masks.CombinedMask()
+
+	start := 0
+	for i := 0; i <= len(query); i++ {
+		if i == len(query) || query[i] == '&' {
+			if start < i {
+				if len(spans) == MaxParamValues {
+					return dst, false
+				}
+				spans = append(spans, rs.newCanonicalSpan(query, start, i, masks, active, urlPath, scratch))
+			}
+			start = i + 1
+		}
+	}
+
+	mode := rs.decodingMode
+	byRule := rs.canonicalMode == CanonicalByRule
+	slices.SortStableFunc(spans, func(a, b canonicalSpan) int {
+		if byRule {
+			if a.group != b.group {
+				return int(a.group) - int(b.group)
+			}
+			if a.position != b.position {
+				return int(a.position - b.position)
+			}
+		}
+		return compareDecoded(query[a.start:a.keyEnd], query[b.start:b.keyEnd], mode)
+	})
+
+	first := true
+	for i, span := range spans {
+		if hasCanonicalDuplicate(query, spans[:i], span, mode) {
+			continue
+		}
+		if !first {
+			dst = append(dst, '&')
+		}
+		first = false
+
+		dst = appendCanonicalComponent(dst, query[span.start:span.keyEnd], mode)
+		if span.keyEnd < span.end {
+			dst = append(dst, '=')
+			dst = appendCanonicalComponent(dst, query[span.keyEnd+1:span.end], mode)
+		}
+	}
+	return dst, true
+}
+
+// newCanonicalSpan builds sort key of query segment from source and position of its rule
+func (rs *ruleSnapshot) newCanonicalSpan(query []byte, start, end int, masks ParamMasks, active ParamMask, urlPath string, scratch []byte) canonicalSpan {
+	span := canonicalSpan{start: uint16(start), keyEnd: uint16(end), end: uint16(end), group: uint8(SourceSpecificURL) + 1}
+	for i := start; i < end; i++ {
+		if query[i] == '=' {
+			span.keyEnd = uint16(i)
+			break
+		}
+	}
+
+	idx := rs.segmentIndexBytes(query[start:end], active, scratch)
+	if idx == -1 || !active.GetBit(idx) {
+		return span
+	}
+	if rule := rs.findParamRuleByIndex(idx, masks, urlPath); rule != nil {
+		// Specific URL rule comes first, globals last
+		span.group = uint8(SourceSpecificURL - masks.GetRuleSource(idx))
+		span.position = int32(rule.position)
+	}
+	return span
+}
+
+// hasCanonicalDuplicate checks if sorted spans already hold segment with equal decoded key and value
+// Segments with equal keys are adjacent after sorting, so only the trailing run is scanned
+func hasCanonicalDuplicate(query []byte, sorted []canonicalSpan, span canonicalSpan, mode DecodingMode) bool {
+	key, value := canonicalComponents(query, span)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		otherKey, otherValue := canonicalComponents(query, sorted[i])
+		if compareDecoded(key, otherKey, mode) != 0 {
+			return false
+		}
+		if compareDecoded(value, otherValue, mode) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalComponents returns raw key and value of span, value being empty for key-only segment
+func canonicalComponents(query []byte, span canonicalSpan) ([]byte, []byte) {
+	if span.keyEnd == span.end {
+		return query[span.start:span.end], nil
+	}
+	return query[span.start:span.keyEnd], query[span.keyEnd+1 : span.end]
+}
+
+// compareDecoded compares two components by their decoded bytes
+func compareDecoded(a, b []byte, mode DecodingMode) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		var ca, cb byte
+		ca, i = nextDecodedByte(a, i, mode)
+		cb, j = nextDecodedByte(b, j, mode)
+		if ca != cb {
+			return int(ca) - int(cb)
+		}
+	}
+	switch {
+	case i < len(a):
+		return 1
+	case j < len(b):
+		return -1
+	}
+	return 0
+}
+
+// nextDecodedByte returns decoded byte at position i of component and position after it
+// Malformed escapes are taken literally
+func nextDecodedByte(component []byte, i int, mode DecodingMode) (byte, int) {
+	switch c := component[i]; {
+	case c == '%' && mode != DecodeNone && i+2 < len(component):
+		hi, okHi := unhex(component[i+1])
+		lo, okLo := unhex(component[i+2])
+		if okHi && okLo {
+			return hi<<4 | lo, i + 3
+		}
+	case c == '+' && mode == DecodeQuery:
+		return ' ', i + 1
+	}
+	return component[i], i + 1
+}
+
+// appendCanonicalComponent appends component percent-encoding every byte except unreserved ones
+// Without decoding escapes are kept as is and only bytes escaped by escapesInQuery are encoded,
+// since rules matched raw bytes
+func appendCanonicalComponent(dst, component []byte, mode DecodingMode) []byte {
+	for i := 0; i < len(component); {
+		if mode == DecodeNone {
+			if c := component[i]; escapesInQuery(c) {
+				dst = appendEscapedByte(dst, c)
+			} else {
+				dst = append(dst, c)
+			}
+			i++
+			continue
+		}
+
+		var c byte
+		c, i = nextDecodedByte(component, i, mode)
+		dst = appendEscapedByte(dst, c)
+	}
+	return dst
+}
+
+// escapesInQuery checks if URL standard percent-encodes byte in query
+func escapesInQuery(c byte) bool {
+	return c <= ' ' || c >= 0x7f || c == '"' || c == '#' || c == '<' || c == '>'
+}
+
+// appendEscapedByte appends byte as is when unreserved, percent-encoded with uppercase hex otherwise
+func appendEscapedByte(dst []byte, c byte) []byte {
+	const upperHex = "0123456789ABCDEF"
+	if isUnreservedByte(c) {
+		return append(dst, c)
+	}
+	return append(dst, '%', upperHex[c>>4], upperHex[c&15])
+}
+
+// isUnreservedByte checks if byte is unreserved URI character
+func isUnreservedByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// canonicalQueryLen returns length of canonical form of query before duplicates are collapsed
+func canonicalQueryLen(query []byte, mode DecodingMode) int {
+	length := 0
+	inValue := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '&':
+			inValue = false
+			length++
+			i++
+		case c == '=' && !inValue:
+			inValue = true
+			length++
+			i++
+		case mode == DecodeNone:
+			length++
+			if escapesInQuery(c) {
+				length += 2
+			}
+			i++
+		default:
+			c, i = nextDecodedByte(query, i, mode)
+			length++
+			if !isUnreservedByte(c) {
+				length += 2
+			}
+		}
+	}
+	return length
+}
@@ -0,0 +1,167 @@
+package paramvalidator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalByName(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&sort=[name,date]&page=[*]; tag=[*]",
+		WithCanonicalMode(CanonicalByName))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?sort=name&q=shoes", "/search?q=shoes&sort=name"},
+		{"/search?q=shoes&sort=name", "/search?q=shoes&sort=name"},
+		{"/search?page=2&debug=1&q=a&tag=x", "/search?page=2&q=a&tag=x"},
+		{"/search?tag=b&tag=a&tag=b", "/search?tag=b&tag=a"},
+		{"/search?q=a&q=a&q", "/search?q=a&q"},
+		{"/search?sort=bogus", "/search"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	if result := pv.FilterQuery("/search", "sort=date&page=1&q=x"); result != "page=1&q=x&sort=date" {
+		t.Errorf("FilterQuery = %q, expected sorted query", result)
+	}
+
+	buffer := make([]byte, 0, 256)
+	if result := pv.FilterQueryBytes([]byte("/search"), []byte("sort=date&page=1&q=x&page=1"), buffer); string(result) != "page=1&q=x&sort=date" {
+		t.Errorf("FilterQueryBytes = %q, expected sorted query", result)
+	}
+}
+
+func TestCanonicalByRule(t *testing.T) {
+	pv := newTestValidator(t, "lang=[*]; /search?q=[*]&sort=[name,date]&page=[*]; /search/*?color=[*]&q=[*]; ref=[*]",
+		WithCanonicalMode(CanonicalByRule))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?page=2&lang=en&sort=date&q=a", "/search?q=a&sort=date&page=2&lang=en"},
+		{"/search?ref=x&lang=en", "/search?lang=en&ref=x"},
+		{"/search/shoes?ref=x&q=a&color=red", "/search/shoes?color=red&q=a&ref=x"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestCanonicalEncoding(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&\"filter[status]\"=[open,closed]",
+		WithCanonicalMode(CanonicalByName), WithDecoding(DecodeQuery))
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"q=red+shoes", "q=red%20shoes"},
+		{"q=red%20shoes&q=red+shoes", "q=red%20shoes"},
+		{"%71=a%2fb", "q=a%2Fb"},
+		{"q=%7Euser&filter%5Bstatus%5D=open", "filter%5Bstatus%5D=open&q=~user"},
+		{"filter[status]=open&filter%5Bstatus%5D=open", "filter%5Bstatus%5D=open"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterQuery("/search", tt.query); result != tt.expected {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.expected)
+		}
+
+		buffer := make([]byte, 0, 4*len(tt.query))
+		if result := pv.FilterQueryBytes([]byte("/search"), []byte(tt.query), buffer); string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.expected)
+		}
+	}
+
+	// Canonical form is itself accepted and stays unchanged
+	for _, tt := range tests {
+		if !pv.ValidateQuery("/search", tt.expected) {
+			t.Errorf("ValidateQuery(%q) = false, expected canonical query to be valid", tt.expected)
+		}
+		if result := pv.FilterQuery("/search", tt.expected); result != tt.expected {
+			t.Errorf("FilterQuery(%q) = %q, expected canonical query unchanged", tt.expected, result)
+		}
+	}
+
+	if result := pv.FilterQueryBytes([]byte("/search"), []byte("q=a+b"), make([]byte, 0, 8)); result != nil {
+		t.Errorf("FilterQueryBytes = %q, expected nil for buffer too small for canonical form", result)
+	}
+}
+
+func TestCanonicalEncodingWithoutDecoding(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&ids[]=[*]", WithCanonicalMode(CanonicalByName))
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"q=a,b&ids[]=1", "ids[]=1&q=a,b"},
+		{"q=red shoes", "q=red%20shoes"},
+		{"q=caf\xc3\xa9&q=a%2cb", "q=caf%C3%A9&q=a%2cb"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterQuery("/search", tt.query); result != tt.expected {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.expected)
+		}
+
+		// Buffer holding filtered query followed by its canonical form is enough
+		buffer := make([]byte, 0, len(tt.query)+len(tt.expected))
+		if result := pv.FilterQueryBytes([]byte("/search"), []byte(tt.query), buffer); string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.expected)
+		}
+		if !pv.ValidateQuery("/search", tt.expected) {
+			t.Errorf("ValidateQuery(%q) = false, expected canonical query to be valid", tt.expected)
+		}
+	}
+}
+
+func TestCanonicalManyParams(t *testing.T) {
+	// More segments than canonical ordering sorts on stack
+	var rules, names []string
+	for i := 0; i < 2*canonicalStackSpans; i++ {
+		rules = append(rules, fmt.Sprintf("p%02d=[*]", i))
+		names = append(names, fmt.Sprintf("p%02d=1", i))
+	}
+
+	pv := newTestValidator(t, "/api?"+strings.Join(rules, "&"), WithCanonicalMode(CanonicalByName))
+	expected := strings.Join(names, "&")
+	slices.Reverse(names)
+	query := strings.Join(names, "&")
+
+	if result := pv.FilterQuery("/api", query); result != expected {
+		t.Errorf("FilterQuery = %q, expected %q", result, expected)
+	}
+	buffer := make([]byte, 0, 2*len(query))
+	if result := pv.FilterQueryBytes([]byte("/api"), []byte(query), buffer); string(result) != expected {
+		t.Errorf("FilterQueryBytes = %q, expected %q", result, expected)
+	}
+}
+
+func TestCanonicalAllowAll(t *testing.T) {
+	pv := newTestValidator(t, "/api?*", WithCanonicalMode(CanonicalByName))
+
+	if result := pv.FilterURL("/api?z=1&a=2&m=3&a=2"); result != "/api?a=2&m=3&z=1" {
+		t.Errorf("FilterURL = %q, expected sorted query", result)
+	}
+}
+
+func TestCanonicalOffKeepsOrder(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&sort=[name,date]")
+
+	if result := pv.FilterURL("/search?sort=name&q=a&q=a"); result != "/search?sort=name&q=a&q=a" {
+		t.Errorf("FilterURL = %q, expected client order", result)
+	}
+}
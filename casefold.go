@@ -0,0 +1,161 @@
+// casefold.go
+package paramvalidator
+
+import (
+	"slices"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CaseFolding selects parts of query matched without regard to case
+type CaseFolding int
+
+const (
+	// CaseFoldNone matches keys and values exactly (default)
+	CaseFoldNone CaseFolding = 0
+	// CaseFoldKeys matches query keys against parameter names case-insensitively
+	CaseFoldKeys CaseFolding = 1 << (iota - 1)
+	// CaseFoldValues compares enum and discriminator values case-insensitively
+	CaseFoldValues
+	// CaseRewriteKeys makes filtering write keys matched by folding in casing declared in rules
+	CaseRewriteKeys
+)
+
+// WithCaseFolding enables case-insensitive matching for whole validator
+// Single rules opt in with :i suffix, e.g. "sort=[name,date]:i"
+func WithCaseFolding(folding CaseFolding) Option {
+	return func(pv *ParamValidator) {
+		pv.caseFolding = folding
+	}
+}
+
+// foldedParamName is exact parameter name matched case-insensitively
+type foldedParamName struct {
+	name  string
+	index int
+}
+
+// addFoldedParamName registers name and aliases of rule for case-insensitive key lookup when folding applies to it
+// Folding is per name, so :i on one rule makes the name case-insensitive wherever it is declared
+func (rs *ruleSnapshot) addFoldedParamName(rule *ParamRule, index int) {
+	if rs.caseFolding&CaseFoldKeys == 0 && !rule.CaseInsensitive || rule.Name == PatternAll {
+		return
+	}
+
+	cr := rs.compiledRules
+	if isParamNamePattern(rule.Name) {
+		for i := range cr.namePatterns {
+			if cr.namePatterns[i].index == index {
+				cr.namePatterns[i].fold = true
+			}
+		}
+		return
+	}
+
+	for _, name := range rule.paramNames() {
+		if !slices.Contains(cr.foldedNames, foldedParamName{name: name, index: index}) {
+			cr.foldedNames = append(cr.foldedNames, foldedParamName{name: name, index: index})
+		}
+	}
+}
+
+// sortFoldedParamNames orders folded names so lookups among names differing only in case are stable
+func (cr *CompiledRules) sortFoldedParamNames() {
+	sort.Slice(cr.foldedNames, func(i, j int) bool {
+		return cr.foldedNames[i].name < cr.foldedNames[j].name
+	})
+}
+
+// lookupFoldedIndex resolves key to index of active parameter name equal to it under case folding, -1 if none
+func lookupFoldedIndex[T ~string | ~[]byte](cr *CompiledRules, key T, active ParamMask) int {
+	for _, folded := range cr.foldedNames {
+		if active.GetBit(folded.index) && equalFold(key, folded.name) {
+			return folded.index
+		}
+	}
+	return -1
+}
+
+// foldsValues checks if enum and discriminator values of rule are compared case-insensitively
+func (rs *ruleSnapshot) foldsValues(rule *ParamRule) bool {
+	return rs.caseFolding&CaseFoldValues != 0 || rule != nil && rule.CaseInsensitive
+}
+
+// foldsKey checks if key of rule may differ in case from declared name
+func (rs *ruleSnapshot) foldsKey(rule *ParamRule) bool {
+	return rs.caseFolding&CaseFoldKeys != 0 || rule != nil && rule.CaseInsensitive
+}
+
+// equalFold reports whether s equals t under simple Unicode case folding without allocations
+func equalFold[T ~string | ~[]byte](s T, t string) bool {
+	i, j := 0, 0
+	for i < len(s) && j < len(t) {
+		var sr, tr rune
+		if s[i] < utf8.RuneSelf {
+			sr, i = rune(s[i]), i+1
+		} else {
+			r, size := decodeRune(s[i:])
+			sr, i = r, i+size
+		}
+		if t[j] < utf8.RuneSelf {
+			tr, j = rune(t[j]), j+1
+		} else {
+			r, size := utf8.DecodeRuneInString(t[j:])
+			tr, j = r, j+size
+		}
+
+		if sr == tr {
+			continue
+		}
+		if tr < sr {
+			sr, tr = tr, sr
+		}
+		if tr < utf8.RuneSelf {
+			if 'A' <= sr && sr <= 'Z' && tr == sr+'a'-'A' {
+				continue
+			}
+			return false
+		}
+
+		r := unicode.SimpleFold(sr)
+		for r != sr && r < tr {
+			r = unicode.SimpleFold(r)
+		}
+		if r != tr {
+			return false
+		}
+	}
+	return i == len(s) && j == len(t)
+}
+
+// decodeRune decodes first rune of s
+func decodeRune[T ~string | ~[]byte](s T) (rune, int) {
+	var buf [utf8.UTFMax]byte
+	n := copy(buf[:], s)
+	return utf8.DecodeRune(buf[:n])
+}
+
+// equalFoldASCII checks if two bytes are equal ignoring case of ASCII letters
+func equalFoldASCII(a, b byte) bool {
+	if a == b {
+		return true
+	}
+	if 'A' <= a && a <= 'Z' {
+		a += 'a' - 'A'
+	}
+	if 'A' <= b && b <= 'Z' {
+		b += 'a' - 'A'
+	}
+	return a == b && 'a' <= a && a <= 'z'
+}
+
+// containsValue checks if values hold value, comparing under case folding when fold is set
+func containsValue[T ~string | ~[]byte](values []string, value T, fold bool) bool {
+	for _, allowedValue := range values {
+		if fold && equalFold(value, allowedValue) || !fold && string(value) == allowedValue {
+			return true
+		}
+	}
+	return false
+}
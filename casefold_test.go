@@ -0,0 +1,167 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestCaseFoldingValidate(t *testing.T) {
+	rules := "/list?page=[range:1..100]&sort=[name,date]&order=[asc,desc]&filter[*]=[*]"
+
+	tests := []struct {
+		name     string
+		folding  CaseFolding
+		query    string
+		expected bool
+	}{
+		{"exact without folding", CaseFoldNone, "page=2&sort=name", true},
+		{"key case without folding", CaseFoldNone, "Page=2", false},
+		{"value case without folding", CaseFoldNone, "sort=NAME", false},
+		{"folded key", CaseFoldKeys, "Page=2&SORT=name", true},
+		{"folded key keeps values strict", CaseFoldKeys, "SORT=NAME", false},
+		{"folded glob name", CaseFoldKeys, "FILTER[status]=open", true},
+		{"folded values keep keys strict", CaseFoldValues, "SORT=NAME", false},
+		{"folded value", CaseFoldValues, "sort=NAME&order=Desc", true},
+		{"folded value still checked", CaseFoldValues, "sort=price", false},
+		{"folded keys and values", CaseFoldKeys | CaseFoldValues, "Page=2&SORT=NAME", true},
+		{"unknown key stays unknown", CaseFoldKeys | CaseFoldValues, "Pages=2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, rules, WithCaseFolding(tt.folding), WithPlugins(plugins.NewRangePlugin()))
+
+			if result := pv.ValidateURL("/list?" + tt.query); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.query, result, tt.expected)
+			}
+			if result := pv.ValidateQuery("/list", tt.query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/list"), []byte(tt.query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", tt.query, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed("/list?" + tt.query); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.query, result.Valid, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCaseFoldingRuleFlag(t *testing.T) {
+	pv := newTestValidator(t, "sort=[name,date]:i; page=[*]; /list?view=[grid,list]:i@first=grid", WithDecoding(DecodeQuery))
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"SORT=Name", true},
+		{"sort=DATE", true},
+		{"sort=price", false},
+		{"Page=2", false},
+		{"VIEW=Grid", true},
+		{"%56iew=LIST", true},
+		{"view=table", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateQuery("/list", tt.query); result != tt.expected {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQueryBytes([]byte("/list"), []byte(tt.query)); result != tt.expected {
+			t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+	}
+}
+
+func TestCaseFoldingUnicode(t *testing.T) {
+	tests := []struct {
+		s, t     string
+		expected bool
+	}{
+		{"Straße", "STRAßE", true},
+		{"ΣΊΣΥΦΟΣ", "σίσυφος", true},
+		{"K", "K", true},
+		{"abc", "abd", false},
+		{"abc", "ab", false},
+		{"\xff", "\xff", true},
+	}
+
+	for _, tt := range tests {
+		if result := equalFold(tt.s, tt.t); result != tt.expected {
+			t.Errorf("equalFold(%q, %q) = %v, expected %v", tt.s, tt.t, result, tt.expected)
+		}
+		if result := equalFold([]byte(tt.s), tt.t); result != tt.expected {
+			t.Errorf("equalFold([]byte(%q), %q) = %v, expected %v", tt.s, tt.t, result, tt.expected)
+		}
+	}
+}
+
+func TestCaseFoldingFilterRewritesKeys(t *testing.T) {
+	rules := "/list?page=[*]&sort=[name,date]&filter[*]=[*]"
+
+	tests := []struct {
+		name     string
+		folding  CaseFolding
+		query    string
+		expected string
+	}{
+		{"keys kept", CaseFoldKeys | CaseFoldValues, "Page=2&SORT=Name", "Page=2&SORT=Name"},
+		{"keys rewritten", CaseFoldKeys | CaseRewriteKeys, "Page=2&SORT=name&x=1", "page=2&sort=name"},
+		{"glob names kept", CaseFoldKeys | CaseRewriteKeys, "FILTER[a]=1", "FILTER[a]=1"},
+		{"rewrite without folding", CaseRewriteKeys, "Page=2&page=3", "page=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, rules, WithCaseFolding(tt.folding))
+
+			if result := pv.FilterQuery("/list", tt.query); result != tt.expected {
+				t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.expected)
+			}
+			buffer := make([]byte, 0, 2*len(tt.query))
+			if result := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), buffer); string(result) != tt.expected {
+				t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCaseFoldingFilterRewritesEncodedKeys(t *testing.T) {
+	pv := newTestValidator(t, "/list?sort_by=[name,date]:i&q=[*]", WithDecoding(DecodeQuery), WithCaseFolding(CaseRewriteKeys))
+
+	if result := pv.FilterURL("/list?SORT%5FBY=Date&q=a+b"); result != "/list?sort_by=Date&q=a+b" {
+		t.Errorf("FilterURL = %q", result)
+	}
+}
+
+func TestCaseFoldingDiscriminators(t *testing.T) {
+	rules := "/search?type=[image,video]:i&q=[*]; variant(type=image) width=[*]; " +
+		"/catalog?category=[shoes,books]:i&sub=[*]; when(category=shoes) sub=[boots]"
+	pv := newTestValidator(t, rules)
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/search?TYPE=Image&width=10", true},
+		{"/search?type=VIDEO&width=10", false},
+		{"/catalog?Category=SHOES&sub=boots", true},
+		{"/catalog?category=Shoes&sub=heels", false},
+		{"/catalog?category=books&sub=heels", true},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestCaseFoldingDefaults(t *testing.T) {
+	pv := newTestValidator(t, "/list?sort=[name,date]:i=Name")
+
+	if result := pv.NormalizeURL("/list"); result != "/list?sort=Name" {
+		t.Errorf("NormalizeURL = %q", result)
+	}
+}
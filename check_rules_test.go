@@ -128,6 +128,741 @@ func TestCheckRules(t *testing.T) {
 			rulesStr:  "level=[range:10-5]",
 			wantError: true,
 		},
+		{
+			name:      "assertion less or equal",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a <= b)",
+			wantError: false,
+		},
+		{
+			name:      "assertion not equal without spaces",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a!=b)",
+			wantError: false,
+		},
+		{
+			name:      "assertion on global params",
+			rulesStr:  "a=[*]&b=[*]; assert(a > b)",
+			wantError: false,
+		},
+		{
+			name:      "assertion unknown operator",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a <> b)",
+			wantError: true,
+		},
+		{
+			name:      "assertion single equals operator",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a = b)",
+			wantError: true,
+		},
+		{
+			name:      "assertion missing operator",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a b)",
+			wantError: true,
+		},
+		{
+			name:      "assertion same operand twice",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a < a)",
+			wantError: true,
+		},
+		{
+			name:      "assertion undeclared operand",
+			rulesStr:  "/p?a=[*]&b=[*]; assert(a < c)",
+			wantError: true,
+		},
+		{
+			name:      "assertion without parentheses",
+			rulesStr:  "/p?a=[*]&b=[*]; assert a < b",
+			wantError: true,
+		},
+		{
+			name:      "assertion missing left operand",
+			rulesStr:  "/p?a=[*]&b=[*]; assert( < b)",
+			wantError: true,
+		},
+		{
+			name:      "case-insensitive flag",
+			rulesStr:  "sort=[name,date]:i",
+			wantError: false,
+		},
+		{
+			name:      "case-insensitive flag with policy and occurrences",
+			rulesStr:  "/list?sort=[name,date]:i@first{0,1}",
+			wantError: false,
+		},
+		{
+			name:      "case-insensitive default differing in case",
+			rulesStr:  "sort=[name,date]:i=Date",
+			wantError: false,
+		},
+		{
+			name:      "default differing in case without flag",
+			rulesStr:  "sort=[name,date]=Date",
+			wantError: true,
+		},
+		{
+			name:      "case-insensitive flag twice",
+			rulesStr:  "sort=[name,date]:i:i",
+			wantError: true,
+		},
+		{
+			name:      "unknown flag",
+			rulesStr:  "sort=[name,date]:x",
+			wantError: true,
+		},
+		{
+			name:      "flag after default value",
+			rulesStr:  "sort=[name,date]=date:i",
+			wantError: true,
+		},
+		{
+			name:      "condition narrowing values",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes) sub=[a,b]",
+			wantError: false,
+		},
+		{
+			name:      "condition with multiple values and params",
+			rulesStr:  "/c?category=[*]&sub=[*]&size=[*]; when(category=shoes,boots) sub=[a]&size=![x]",
+			wantError: false,
+		},
+		{
+			name:      "condition on global params",
+			rulesStr:  "category=[*]&sub=[*]; when(category=shoes) sub=[a,b]",
+			wantError: false,
+		},
+		{
+			name:      "condition without value",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category) sub=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition with empty value",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=) sub=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition without branch rules",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes)",
+			wantError: true,
+		},
+		{
+			name:      "condition without parentheses",
+			rulesStr:  "/c?category=[*]&sub=[*]; when category=shoes sub=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition narrowing its discriminator",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes) category=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition branch param not declared",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes) other=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition discriminator not declared",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(other=shoes) sub=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition branch marks param required",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes) +sub=[a]",
+			wantError: true,
+		},
+		{
+			name:      "condition branch sets occurrences",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes) sub=[a]{1,2}",
+			wantError: true,
+		},
+		{
+			name:      "dependency between params",
+			rulesStr:  "/list?sort=[name]&order=[asc]; requires sort->order",
+			wantError: false,
+		},
+		{
+			name:      "dependency on multiple params after newline",
+			rulesStr:  "/list?a=[*]&b=[*]&c=[*]\nrequires a->b,c",
+			wantError: false,
+		},
+		{
+			name:      "dependency on global param",
+			rulesStr:  "g=[*];/list?a=[*]; requires a->g",
+			wantError: false,
+		},
+		{
+			name:      "dependency target not declared",
+			rulesStr:  "/list?sort=[name]; requires sort->order",
+			wantError: true,
+		},
+		{
+			name:      "dependency without arrow",
+			rulesStr:  "/list?sort=[name]&order=[asc]; requires sort order",
+			wantError: true,
+		},
+		{
+			name:      "dependency without target",
+			rulesStr:  "/list?sort=[name]&order=[asc]; requires sort->",
+			wantError: true,
+		},
+		{
+			name:      "dependency on itself",
+			rulesStr:  "/list?sort=[name]; requires sort->sort",
+			wantError: true,
+		},
+		{
+			name:      "dependency across URL rules",
+			rulesStr:  "/list?a=[*];/other?b=[*]; requires a->b",
+			wantError: true,
+		},
+		{
+			name:      "deprecated param",
+			rulesStr:  "~old=[*]",
+			wantError: false,
+		},
+		{
+			name:      "deprecated inverted param",
+			rulesStr:  "~old=![a,b]",
+			wantError: false,
+		},
+		{
+			name:      "deprecated URL param",
+			rulesStr:  "/list?~old=[*]&page=[*]",
+			wantError: false,
+		},
+		{
+			name:      "deprecated param with alias",
+			rulesStr:  "~old|o=[*]",
+			wantError: false,
+		},
+		{
+			name:      "deprecation marker twice",
+			rulesStr:  "~~old=[*]",
+			wantError: true,
+		},
+		{
+			name:      "required marker before deprecation marker",
+			rulesStr:  "+~old=[*]",
+			wantError: true,
+		},
+		{
+			name:      "required marker after deprecation marker",
+			rulesStr:  "~+old=[*]",
+			wantError: true,
+		},
+		{
+			name:      "deprecation marker without name",
+			rulesStr:  "~",
+			wantError: true,
+		},
+		{
+			name:      "first duplicate policy",
+			rulesStr:  "/search?sort=[name]@first",
+			wantError: false,
+		},
+		{
+			name:      "last duplicate policy with single occurrence",
+			rulesStr:  "/search?sort=[name]@last{0,1}",
+			wantError: false,
+		},
+		{
+			name:      "allow duplicate policy with occurrences",
+			rulesStr:  "/search?sort=[name]{1,5}@allow",
+			wantError: false,
+		},
+		{
+			name:      "reject duplicate policy on global param",
+			rulesStr:  "sort=[*]@reject",
+			wantError: false,
+		},
+		{
+			name:      "unknown duplicate policy",
+			rulesStr:  "/search?sort=[name]@newest",
+			wantError: true,
+		},
+		{
+			name:      "duplicate policy twice",
+			rulesStr:  "/search?sort=[name]@first@last",
+			wantError: true,
+		},
+		{
+			name:      "first policy with repeated occurrences",
+			rulesStr:  "/search?sort=[name]{1,5}@first",
+			wantError: true,
+		},
+		{
+			name:      "reject policy with repeated occurrences",
+			rulesStr:  "/search?sort=[name]{2,}@reject",
+			wantError: true,
+		},
+		{
+			name:      "oneof group",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof(q,sku)",
+			wantError: false,
+		},
+		{
+			name:      "oneof group with spaces",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof (q, sku)",
+			wantError: false,
+		},
+		{
+			name:      "exclusive group",
+			rulesStr:  "/r?d=[*]&f=[*]&t=[*]; exclusive(d | f,t)",
+			wantError: false,
+		},
+		{
+			name:      "atleastone group on global params",
+			rulesStr:  "a=[*]&b=[*]; atleastone(a,b)",
+			wantError: false,
+		},
+		{
+			name:      "group with single param",
+			rulesStr:  "/search?q=[*]; oneof(q)",
+			wantError: true,
+		},
+		{
+			name:      "group without parentheses",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof q,sku",
+			wantError: true,
+		},
+		{
+			name:      "group without closing parenthesis",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof(q,sku",
+			wantError: true,
+		},
+		{
+			name:      "group with repeated param",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof(q,q,sku)",
+			wantError: true,
+		},
+		{
+			name:      "exclusive group with repeated param",
+			rulesStr:  "/search?q=[*]&sku=[*]; exclusive(q | q,sku)",
+			wantError: true,
+		},
+		{
+			name:      "group with undeclared param",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof(q,other)",
+			wantError: true,
+		},
+		{
+			name:      "group with empty alternative",
+			rulesStr:  "/search?q=[*]&sku=[*]; oneof(q | )",
+			wantError: true,
+		},
+		{
+			name:      "occurrence range",
+			rulesStr:  "/search?tags=[*]{1,5}",
+			wantError: false,
+		},
+		{
+			name:      "exact occurrence count",
+			rulesStr:  "/search?tags=[*]{3}",
+			wantError: false,
+		},
+		{
+			name:      "occurrence minimum only",
+			rulesStr:  "/search?tags=[*]{1,}",
+			wantError: false,
+		},
+		{
+			name:      "occurrence maximum only",
+			rulesStr:  "/search?tags=[*]{,4}",
+			wantError: false,
+		},
+		{
+			name:      "occurrences of key-only param",
+			rulesStr:  "/search?flag=[]{0,1}",
+			wantError: false,
+		},
+		{
+			name:      "occurrences of global param",
+			rulesStr:  "tags=[a,b]{1,2}",
+			wantError: false,
+		},
+		{
+			name:      "occurrence minimum above maximum",
+			rulesStr:  "/search?tags=[*]{5,1}",
+			wantError: true,
+		},
+		{
+			name:      "zero occurrences",
+			rulesStr:  "/search?tags=[*]{0}",
+			wantError: true,
+		},
+		{
+			name:      "occurrence range without bounds",
+			rulesStr:  "/search?tags=[*]{,}",
+			wantError: true,
+		},
+		{
+			name:      "non-numeric occurrences",
+			rulesStr:  "/search?tags=[*]{a,b}",
+			wantError: true,
+		},
+		{
+			name:      "unclosed occurrences",
+			rulesStr:  "/search?tags=[*]{1,5",
+			wantError: true,
+		},
+		{
+			name:      "occurrences twice",
+			rulesStr:  "/search?tags=[*]{1,2}{1,3}",
+			wantError: true,
+		},
+		{
+			name:      "junk after constraint",
+			rulesStr:  "/search?tags=[*]junk",
+			wantError: true,
+		},
+		{
+			name:      "variant param",
+			rulesStr:  "/s?type=[a,b]; variant(type=a) x=[*]",
+			wantError: false,
+		},
+		{
+			name:      "variant with multiple values and required param",
+			rulesStr:  "/s?type=[a,b]; variant(type=a,b) +x=[*]{1,2}",
+			wantError: false,
+		},
+		{
+			name:      "variant redeclaring base param",
+			rulesStr:  "/s?type=[a,b]&x=[*]; variant(type=a) x=[1]",
+			wantError: true,
+		},
+		{
+			name:      "variant discriminator not declared",
+			rulesStr:  "/s?type=[a,b]; variant(other=a) x=[*]",
+			wantError: true,
+		},
+		{
+			name:      "variant without params",
+			rulesStr:  "/s?type=[a,b]; variant(type=a)",
+			wantError: true,
+		},
+		{
+			name:      "variant redeclaring discriminator",
+			rulesStr:  "/s?type=[a,b]; variant(type=a) type=[a]",
+			wantError: true,
+		},
+		{
+			name:      "variant on global params",
+			rulesStr:  "type=[a,b]; variant(type=a) x=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias",
+			rulesStr:  "query|q=[*]",
+			wantError: false,
+		},
+		{
+			name:      "aliases with required marker and policy",
+			rulesStr:  "+query|q|search=[*]@first",
+			wantError: false,
+		},
+		{
+			name:      "alias of quoted bracketed name",
+			rulesStr:  "\"filter[status]\"|status=[open,closed]",
+			wantError: false,
+		},
+		{
+			name:      "alias of inverted param",
+			rulesStr:  "query|q=![spam]",
+			wantError: false,
+		},
+		{
+			name:      "same alias in separate URL rules",
+			rulesStr:  "/a?query|q=[*]; /b?query|q=[len:1..5]",
+			wantError: false,
+		},
+		{
+			name:      "empty alias",
+			rulesStr:  "query|=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias equal to name",
+			rulesStr:  "query|query=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias repeated",
+			rulesStr:  "query|q|q=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias of glob name",
+			rulesStr:  "utm_*|u=[*]",
+			wantError: true,
+		},
+		{
+			name:      "glob alias",
+			rulesStr:  "query|u*=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias colliding with param",
+			rulesStr:  "query|q=[*]; q=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias colliding across URL rules",
+			rulesStr:  "/a?query|q=[*]; /b?search|q=[*]",
+			wantError: true,
+		},
+		{
+			name:      "alias in condition branch",
+			rulesStr:  "/c?category=[*]&sub=[*]; when(category=shoes) sub|s=[a]",
+			wantError: true,
+		},
+		{
+			name:      "mapping to allowed value",
+			rulesStr:  "sort=[date_desc,price_asc; newest->date_desc]",
+			wantError: false,
+		},
+		{
+			name:      "mapping on single value",
+			rulesStr:  "sort=[date_desc; newest->date_desc]",
+			wantError: false,
+		},
+		{
+			name:      "mappings without space",
+			rulesStr:  "/list?view=[grid,list; 1->grid,2->list]&q=[*]",
+			wantError: false,
+		},
+		{
+			name:      "mapping to value not allowed",
+			rulesStr:  "sort=[date_desc,price_asc; newest->oldest]",
+			wantError: true,
+		},
+		{
+			name:      "mapping from allowed value",
+			rulesStr:  "sort=[date_desc,price_asc; date_desc->price_asc]",
+			wantError: true,
+		},
+		{
+			name:      "mapping from same value twice",
+			rulesStr:  "sort=[date_desc,price_asc; newest->date_desc, newest->price_asc]",
+			wantError: true,
+		},
+		{
+			name:      "mapping without source",
+			rulesStr:  "sort=[date_desc,price_asc; ->date_desc]",
+			wantError: true,
+		},
+		{
+			name:      "mapping on inverted param",
+			rulesStr:  "sort=![date_desc,price_asc; newest->date_desc]",
+			wantError: true,
+		},
+		{
+			name:      "mapping on wildcard",
+			rulesStr:  "sort=[*; newest->date_desc]",
+			wantError: true,
+		},
+		{
+			name:      "bracketed name with empty brackets",
+			rulesStr:  "/search?ids[]=[*]",
+			wantError: false,
+		},
+		{
+			name:      "quoted bracketed name",
+			rulesStr:  `/search?"filter[status]"=[open]`,
+			wantError: false,
+		},
+		{
+			name:      "quoted bracketed key-only name",
+			rulesStr:  `"filter[status]"`,
+			wantError: false,
+		},
+		{
+			name:      "nested bracketed name",
+			rulesStr:  "filter[a][b]=[x]",
+			wantError: false,
+		},
+		{
+			name:      "bracketed glob name with occurrences",
+			rulesStr:  "filter[*]=[x]{0,3}",
+			wantError: false,
+		},
+		{
+			name:      "required bracketed key-only name",
+			rulesStr:  "+ids[]=[]",
+			wantError: false,
+		},
+		{
+			name:      "bracketed name without base",
+			rulesStr:  "[a]=[x]",
+			wantError: true,
+		},
+		{
+			name:      "bracketed name with dot",
+			rulesStr:  "filter[a.b]=[x]",
+			wantError: true,
+		},
+		{
+			name:      "unclosed bracketed name",
+			rulesStr:  "filter[a=[x]",
+			wantError: true,
+		},
+		{
+			name:      "unclosed quoted name",
+			rulesStr:  `"filter[status]=[open]`,
+			wantError: true,
+		},
+		{
+			name:      "quoted name with separator",
+			rulesStr:  `"filter[a&b]"=[x]`,
+			wantError: true,
+		},
+		{
+			name:      "glob names",
+			rulesStr:  "utm_*=[len:..128]&x-*=[*]",
+			wantError: false,
+		},
+		{
+			name:      "glob name with leading wildcard",
+			rulesStr:  "*_id=[*]",
+			wantError: false,
+		},
+		{
+			name:      "glob bracketed name",
+			rulesStr:  "utm_*[*]=[x]",
+			wantError: false,
+		},
+		{
+			name:      "glob name with question mark",
+			rulesStr:  "utm_?=[x]",
+			wantError: true,
+		},
+		{
+			name:      "required param",
+			rulesStr:  "/search?+q=[*]",
+			wantError: false,
+		},
+		{
+			name:      "required global param",
+			rulesStr:  "+token=[?]",
+			wantError: false,
+		},
+		{
+			name:      "required key-only param",
+			rulesStr:  "/api?+debug=[]",
+			wantError: false,
+		},
+		{
+			name:      "required inverted param",
+			rulesStr:  "/search?+q=![test]",
+			wantError: true,
+		},
+		{
+			name:      "required marker twice",
+			rulesStr:  "/search?++q=[*]",
+			wantError: true,
+		},
+		{
+			name:      "required marker without name",
+			rulesStr:  "/search?+",
+			wantError: true,
+		},
+		{
+			name:      "default value from enum",
+			rulesStr:  "/list?page=[1,2,3]=1",
+			wantError: false,
+		},
+		{
+			name:      "default value of wildcard",
+			rulesStr:  "/list?page=[*]=10",
+			wantError: false,
+		},
+		{
+			name:      "default value with policy and occurrences",
+			rulesStr:  "/list?page=[*]{0,1}@first=10",
+			wantError: false,
+		},
+		{
+			name:      "default value of inverted param",
+			rulesStr:  "/list?page=![0]=1",
+			wantError: false,
+		},
+		{
+			name:      "default value not in enum",
+			rulesStr:  "/list?page=[1,2,3]=4",
+			wantError: true,
+		},
+		{
+			name:      "default value rejected by inverted param",
+			rulesStr:  "/list?page=![1]=1",
+			wantError: true,
+		},
+		{
+			name:      "empty default value",
+			rulesStr:  "/list?page=[1,2]=",
+			wantError: true,
+		},
+		{
+			name:      "default value of key-only param",
+			rulesStr:  "/list?flag=[]=1",
+			wantError: true,
+		},
+		{
+			name:      "default value of glob param",
+			rulesStr:  "/list?utm_*=[*]=x",
+			wantError: true,
+		},
+		{
+			name:      "policy after default value",
+			rulesStr:  "/list?page=[1,2]=1@first",
+			wantError: true,
+		},
+		{
+			name:      "default value in condition branch",
+			rulesStr:  "/list?category=[*]&sub=[*]; when(category=a) sub=[x]=x",
+			wantError: true,
+		},
+		{
+			name:      "default value surrounded by spaces",
+			rulesStr:  "/list?sort=[*]= asc ",
+			wantError: false,
+		},
+		{
+			name:      "default values of bracketed names",
+			rulesStr:  "/list?ids[]=[*]=1&\"f[s]\"=[*]=2",
+			wantError: false,
+		},
+		{
+			name:      "default value with space",
+			rulesStr:  "/list?sort=[*]=a b",
+			wantError: true,
+		},
+		{
+			name:      "default value with space before next param",
+			rulesStr:  "/list?sort=[*]=a b&page=[*]",
+			wantError: true,
+		},
+		{
+			name:      "inverted default value with space",
+			rulesStr:  "/list?page=![0]=1 0",
+			wantError: true,
+		},
+		{
+			name:      "global default value with space",
+			rulesStr:  "sort=[*]=a b",
+			wantError: true,
+		},
+		{
+			name:      "transformed default not in enum",
+			rulesStr:  "/list?sort=[lower|a,b]=c",
+			wantError: true,
+		},
+		{
+			name:      "trimmed default not in enum",
+			rulesStr:  "/list?sort=[trim|a,b]=+a",
+			wantError: true,
+		},
+		{
+			name:      "empty transform enum",
+			rulesStr:  "/list?sort=[lower|]",
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,407 @@
+// clause.go
+package paramvalidator
+
+import "strings"
+
+// clauseKeywords lists statements declaring cross-parameter constraints
+var clauseKeywords = []string{"requires", "oneof", "exclusive", "atleastone", "assert", "when", "variant"}
+
+// compiledClauses are clauses resolved to parameter indices
+type compiledClauses struct {
+	dependencies []compiledDependency
+	groups       []compiledGroup
+	assertions   []compiledAssertion
+	conditions   []compiledCondition
+}
+
+// compiledDependency is dependency resolved to parameter indices
+type compiledDependency struct {
+	param      int
+	requires   ParamMask
+	dependency Dependency
+}
+
+// compiledGroup is parameter group resolved to masks, one per alternative
+type compiledGroup struct {
+	alternatives []ParamMask
+	members      ParamMask
+	group        ParamGroup
+}
+
+// String returns dependency in rule syntax
+func (d Dependency) String() string {
+	return "requires " + d.Param + "->" + strings.Join(d.Requires, ",")
+}
+
+// String returns rule syntax keyword of group kind
+func (gk GroupKind) String() string {
+	switch gk {
+	case GroupOneOf:
+		return "oneof"
+	case GroupExclusive:
+		return "exclusive"
+	case GroupAtLeastOne:
+		return "atleastone"
+	default:
+		return "unknown"
+	}
+}
+
+// String returns group in rule syntax
+func (g ParamGroup) String() string {
+	alternatives := make([]string, len(g.Alternatives))
+	for i, alternative := range g.Alternatives {
+		alternatives[i] = strings.Join(alternative, ",")
+	}
+	return g.Kind.String() + "(" + strings.Join(alternatives, " | ") + ")"
+}
+
+// paramNames returns every parameter name referenced by clauses
+func (rc *RuleClauses) paramNames() []string {
+	var names []string
+	for _, dependency := range rc.Dependencies {
+		names = append(names, dependency.Param)
+		names = append(names, dependency.Requires...)
+	}
+	for _, group := range rc.Groups {
+		for _, alternative := range group.Alternatives {
+			names = append(names, alternative...)
+		}
+	}
+	for _, assertion := range rc.Assertions {
+		names = append(names, assertion.Left, assertion.Right)
+	}
+	for _, condition := range rc.Conditions {
+		names = append(names, condition.Param)
+		for name := range condition.Params {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isEmpty checks if no clauses are declared
+func (rc *RuleClauses) isEmpty() bool {
+	return len(rc.Dependencies) == 0 && len(rc.Groups) == 0 && len(rc.Assertions) == 0 && len(rc.Conditions) == 0
+}
+
+// copyRuleClauses creates a deep copy of clauses
+func (rs *ruleSnapshot) copyRuleClauses(clauses RuleClauses) RuleClauses {
+	var clausesCopy RuleClauses
+	for _, dependency := range clauses.Dependencies {
+		clausesCopy.Dependencies = append(clausesCopy.Dependencies, Dependency{
+			Param:    dependency.Param,
+			Requires: append([]string(nil), dependency.Requires...),
+		})
+	}
+	for _, group := range clauses.Groups {
+		groupCopy := ParamGroup{Kind: group.Kind}
+		for _, alternative := range group.Alternatives {
+			groupCopy.Alternatives = append(groupCopy.Alternatives, append([]string(nil), alternative...))
+		}
+		clausesCopy.Groups = append(clausesCopy.Groups, groupCopy)
+	}
+	clausesCopy.Assertions = append([]Assertion(nil), clauses.Assertions...)
+	for _, condition := range clauses.Conditions {
+		clausesCopy.Conditions = append(clausesCopy.Conditions, rs.copyCondition(condition))
+	}
+	for _, variant := range clauses.Variants {
+		clausesCopy.Variants = append(clausesCopy.Variants, rs.copyCondition(variant))
+	}
+	return clausesCopy
+}
+
+// copyCondition creates a deep copy of condition
+func (rs *ruleSnapshot) copyCondition(condition Condition) Condition {
+	conditionCopy := Condition{
+		Param:  condition.Param,
+		Values: append([]string(nil), condition.Values...),
+		Params: make(map[string]*ParamRule, len(condition.Params)),
+	}
+	for name, rule := range condition.Params {
+		conditionCopy.Params[name] = rs.copyParamRuleUnsafe(rule)
+	}
+	return conditionCopy
+}
+
+// compileClauses resolves clause parameter names to indices
+// params holds parameters declared by URL rule of clauses, nil for global clauses
+func (rs *ruleSnapshot) compileClauses(clauses RuleClauses, params map[string]*ParamRule) compiledClauses {
+	var compiled compiledClauses
+
+	for _, dependency := range clauses.Dependencies {
+		idx := rs.paramIndex.GetIndex(dependency.Param)
+		if idx == -1 {
+			continue
+		}
+		compiled.dependencies = append(compiled.dependencies, compiledDependency{
+			param:      idx,
+			requires:   rs.namesMask(dependency.Requires),
+			dependency: dependency,
+		})
+	}
+
+	for _, group := range clauses.Groups {
+		compiledGroup := compiledGroup{members: NewParamMask(), group: group}
+		for _, alternative := range group.Alternatives {
+			mask := rs.namesMask(alternative)
+			compiledGroup.alternatives = append(compiledGroup.alternatives, mask)
+			compiledGroup.members = compiledGroup.members.Union(mask)
+		}
+		compiled.groups = append(compiled.groups, compiledGroup)
+	}
+
+	for _, assertion := range clauses.Assertions {
+		left, right := rs.paramIndex.GetIndex(assertion.Left), rs.paramIndex.GetIndex(assertion.Right)
+		if left == -1 || right == -1 {
+			continue
+		}
+		compiled.assertions = append(compiled.assertions, compiledAssertion{
+			left:            left,
+			right:           right,
+			leftTransforms:  rs.declaredTransforms(assertion.Left, params),
+			rightTransforms: rs.declaredTransforms(assertion.Right, params),
+			assertion:       assertion,
+		})
+	}
+
+	for _, condition := range clauses.Conditions {
+		if compiledCondition, ok := rs.compileCondition(condition, rs.declaredRule(condition.Param, params)); ok {
+			compiled.conditions = append(compiled.conditions, compiledCondition)
+		}
+	}
+
+	return compiled
+}
+
+// namesMask creates mask of indexed parameter names
+func (rs *ruleSnapshot) namesMask(names []string) ParamMask {
+	mask := NewParamMask()
+	for _, name := range names {
+		mask.SetBit(rs.paramIndex.GetIndex(name))
+	}
+	return mask
+}
+
+// visitClauses calls visit for clauses applying to URL path
+// Global clauses come first, followed by those of every matching URL rule
+func (rs *ruleSnapshot) visitClauses(urlPath string, visit func(clauses *compiledClauses, urlRule *URLRule)) {
+	if rs.compiledRules == nil || !rs.compiledRules.hasClauses {
+		return
+	}
+
+	visit(&rs.compiledRules.globalClauses, nil)
+	for _, urlRule := range rs.compiledRules.clauseRules {
+		if rs.urlMatchesPatternUnsafe(urlPath, urlRule.URLPattern) {
+			visit(&urlRule.clauses, urlRule)
+		}
+	}
+}
+
+// presentAlternatives counts group alternatives with at least one member present
+func (cg *compiledGroup) presentAlternatives(present ParamMask) int {
+	count := 0
+	for _, alternative := range cg.alternatives {
+		if !alternative.Intersection(present).IsEmpty() {
+			count++
+		}
+	}
+	return count
+}
+
+// satisfied checks number of present alternatives against group kind
+func (cg *compiledGroup) satisfied(present ParamMask) bool {
+	count := cg.presentAlternatives(present)
+	switch cg.group.Kind {
+	case GroupOneOf:
+		return count == 1
+	case GroupExclusive:
+		return count <= 1
+	case GroupAtLeastOne:
+		return count >= 1
+	}
+	return true
+}
+
+// clausesSatisfied checks dependencies, groups and assertions against accepted parameters
+func (rs *ruleSnapshot) clausesSatisfied(qt *queryTracker, urlPath string) bool {
+	present := qt.present
+	satisfied := true
+	rs.visitClauses(urlPath, func(clauses *compiledClauses, _ *URLRule) {
+		for i := range clauses.dependencies {
+			dependency := &clauses.dependencies[i]
+			if present.GetBit(dependency.param) && !present.Contains(dependency.requires) {
+				satisfied = false
+			}
+		}
+		for i := range clauses.groups {
+			if !clauses.groups[i].satisfied(present) {
+				satisfied = false
+			}
+		}
+		for i := range clauses.assertions {
+			if !rs.assertionHolds(qt, &clauses.assertions[i]) {
+				satisfied = false
+			}
+		}
+	})
+	return satisfied
+}
+
+// clauseDrops returns parameters filtering removes to satisfy clauses
+// Conflicting groups keep the alternative seen first in query, failed assertions
+// drop their right operand, then dependents with missing prerequisites are dropped until stable
+func (rs *ruleSnapshot) clauseDrops(qt *queryTracker, urlPath string) ParamMask {
+	kept := qt.present
+
+	rs.visitClauses(urlPath, func(clauses *compiledClauses, _ *URLRule) {
+		for i := range clauses.groups {
+			group := &clauses.groups[i]
+			if group.group.Kind == GroupAtLeastOne || group.presentAlternatives(kept) <= 1 {
+				continue
+			}
+
+			first := -1
+			firstSeen := 0
+			for j, alternative := range group.alternatives {
+				if seen := qt.firstSeenIn(alternative.Intersection(kept)); seen != 0 && (first == -1 || seen < firstSeen) {
+					first, firstSeen = j, seen
+				}
+			}
+			for j, alternative := range group.alternatives {
+				if j != first {
+					kept = kept.Difference(alternative)
+				}
+			}
+		}
+
+		for i := range clauses.assertions {
+			assertion := &clauses.assertions[i]
+			if kept.GetBit(assertion.left) && kept.GetBit(assertion.right) && !rs.assertionHolds(qt, assertion) {
+				kept.ClearBit(assertion.right)
+			}
+		}
+	})
+
+	for changed := true; changed; {
+		changed = false
+		rs.visitClauses(urlPath, func(clauses *compiledClauses, _ *URLRule) {
+			for i := range clauses.dependencies {
+				dependency := &clauses.dependencies[i]
+				if kept.GetBit(dependency.param) && !kept.Contains(dependency.requires) {
+					kept.ClearBit(dependency.param)
+					changed = true
+				}
+			}
+		})
+	}
+
+	return qt.present.Difference(kept)
+}
+
+// dropUnsatisfiedClauses removes filtered segments of parameters dropped to satisfy clauses
+func (rs *ruleSnapshot) dropUnsatisfiedClauses(qt *queryTracker, filtered []byte, masks ParamMasks, urlPath string, scratch []byte) []byte {
+	if rs.compiledRules == nil || !rs.compiledRules.hasClauses {
+		return filtered
+	}
+	drop := rs.clauseDrops(qt, urlPath)
+	if drop.IsEmpty() {
+		return filtered
+	}
+
+	qt.present = qt.present.Difference(drop)
+	return rs.removeSegments(filtered, drop, masks.CombinedMask(), scratch)
+}
+
+// removeSegments removes query segments of parameters in drop mask, compacting query in place
+func (rs *ruleSnapshot) removeSegments(query []byte, drop, active ParamMask, scratch []byte) []byte {
+	result := query[:0]
+	start := 0
+	for i := 0; i <= len(query); i++ {
+		if i == len(query) || query[i] == '&' {
+			if start < i {
+				segment := query[start:i]
+				if !drop.GetBit(rs.segmentIndexBytes(segment, active, scratch)) {
+					if len(result) > 0 {
+						result = append(result, '&')
+					}
+					result = append(result, segment...)
+				}
+			}
+			start = i + 1
+		}
+	}
+	return result
+}
+
+// collectClauseViolations records violation for every unsatisfied dependency, group and assertion
+// Dependencies and groups use present mask, assertions compare values accepted by tracker
+func (rs *ruleSnapshot) collectClauseViolations(result *ValidationResult, present ParamMask, qt *queryTracker, urlPath string) {
+	rs.visitClauses(urlPath, func(clauses *compiledClauses, urlRule *URLRule) {
+		violation := Violation{Source: SourceGlobal}
+		if urlRule != nil {
+			violation.Source = SourceURL
+			violation.URLPattern = urlRule.URLPattern
+		}
+
+		for i := range clauses.dependencies {
+			dependency := &clauses.dependencies[i]
+			if !present.GetBit(dependency.param) || present.Contains(dependency.requires) {
+				continue
+			}
+
+			violation.Param = dependency.dependency.Param
+			violation.Value = strings.Join(rs.filterNames(dependency.dependency.Requires, present, false), ",")
+			violation.Kind = ViolationDependency
+			violation.Clause = dependency.dependency.String()
+			result.addViolation(violation)
+		}
+
+		for i := range clauses.groups {
+			group := &clauses.groups[i]
+			if group.satisfied(present) {
+				continue
+			}
+
+			var members []string
+			for _, alternative := range group.group.Alternatives {
+				members = append(members, alternative...)
+			}
+
+			violation.Param = ""
+			violation.Kind = ViolationGroupMissing
+			violation.Value = strings.Join(members, ",")
+			if group.presentAlternatives(present) > 1 {
+				violation.Kind = ViolationGroupConflict
+				violation.Value = strings.Join(rs.filterNames(members, present, true), ",")
+			}
+			violation.Clause = group.group.String()
+			result.addViolation(violation)
+		}
+
+		for i := range clauses.assertions {
+			assertion := &clauses.assertions[i]
+			if rs.assertionHolds(qt, assertion) {
+				continue
+			}
+
+			violation.Param = assertion.assertion.Left
+			violation.Value = string(rs.spanValue(qt, qt.state.spans[assertion.left], nil)) + "," +
+				string(rs.spanValue(qt, qt.state.spans[assertion.right], nil))
+			violation.Kind = ViolationAssertion
+			violation.Clause = assertion.assertion.String()
+			result.addViolation(violation)
+		}
+	})
+}
+
+// filterNames returns names whose presence in mask equals wantPresent
+func (rs *ruleSnapshot) filterNames(names []string, present ParamMask, wantPresent bool) []string {
+	var filtered []string
+	for _, name := range names {
+		if present.GetBit(rs.compiledRules.paramIndex.GetIndex(name)) == wantPresent {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
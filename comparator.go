@@ -0,0 +1,206 @@
+// comparator.go
+package paramvalidator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DiffKind flags verdicts that differ between current and candidate rules
+type DiffKind int
+
+const (
+	// DiffValidate flags URL accepted by one validator and rejected by the other
+	DiffValidate DiffKind = 1 << iota
+	// DiffFilter flags URL filtered to different outputs
+	DiffFilter
+)
+
+// String returns human readable name of differing verdicts
+func (dk DiffKind) String() string {
+	switch dk {
+	case DiffValidate:
+		return "validate"
+	case DiffFilter:
+		return "filter"
+	case DiffValidate | DiffFilter:
+		return "validate+filter"
+	default:
+		return "none"
+	}
+}
+
+// DiffEvent describes URL whose verdicts differ between current and candidate rules
+// Live calls fill only fields of compared operation, offline comparison fills all of them
+type DiffEvent struct {
+	URL               string
+	Kind              DiffKind
+	CurrentValid      bool
+	CandidateValid    bool
+	CurrentFiltered   string
+	CandidateFiltered string
+}
+
+// DiffObserver receives diff events emitted on live traffic
+type DiffObserver func(event DiffEvent)
+
+// ComparatorOption configures comparator
+type ComparatorOption func(*Comparator)
+
+// WithDiffSampling sets fraction of live calls also run against candidate rules
+func WithDiffSampling(rate float64) ComparatorOption {
+	return func(c *Comparator) {
+		c.sampleRate = min(max(rate, 0), 1)
+	}
+}
+
+// WithDiffRateLimit limits diff events passed to observer to limit per interval
+func WithDiffRateLimit(limit int, interval time.Duration) ComparatorOption {
+	return func(c *Comparator) {
+		c.limit = limit
+		c.interval = interval
+	}
+}
+
+// Comparator runs current and candidate validators side by side and reports differing verdicts
+// Live calls return verdict of current validator
+type Comparator struct {
+	current    *ParamValidator
+	candidate  *ParamValidator
+	observer   DiffObserver
+	sampleRate float64
+	limit      int
+	interval   time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	emitted     int
+	dropped     atomic.Uint64
+}
+
+// NewComparator creates comparator of current and candidate validators
+func NewComparator(current, candidate *ParamValidator, observer DiffObserver, options ...ComparatorOption) (*Comparator, error) {
+	if current == nil || candidate == nil {
+		return nil, fmt.Errorf("comparator requires current and candidate validators")
+	}
+
+	c := &Comparator{
+		current:    current,
+		candidate:  candidate,
+		observer:   observer,
+		sampleRate: 1,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
+}
+
+// ValidateURL validates URL with current rules and emits diff event if candidate rules disagree
+func (c *Comparator) ValidateURL(fullURL string) bool {
+	valid := c.current.ValidateURL(fullURL)
+	if !c.sampled() {
+		return valid
+	}
+
+	if candidateValid := c.candidate.ValidateURL(fullURL); candidateValid != valid {
+		c.emit(DiffEvent{URL: fullURL, Kind: DiffValidate, CurrentValid: valid, CandidateValid: candidateValid})
+	}
+	return valid
+}
+
+// FilterURL filters URL with current rules and emits diff event if candidate rules disagree
+func (c *Comparator) FilterURL(fullURL string) string {
+	filtered := c.current.FilterURL(fullURL)
+	if !c.sampled() {
+		return filtered
+	}
+
+	if candidateFiltered := c.candidate.FilterURL(fullURL); candidateFiltered != filtered {
+		c.emit(DiffEvent{URL: fullURL, Kind: DiffFilter, CurrentFiltered: filtered, CandidateFiltered: candidateFiltered})
+	}
+	return filtered
+}
+
+// Compare runs both validators on URL and returns their verdicts without sampling or rate limiting
+func (c *Comparator) Compare(fullURL string) (DiffEvent, bool) {
+	return diffEvent(DiffEvent{
+		URL:               fullURL,
+		CurrentValid:      c.current.ValidateURL(fullURL),
+		CandidateValid:    c.candidate.ValidateURL(fullURL),
+		CurrentFiltered:   c.current.FilterURL(fullURL),
+		CandidateFiltered: c.candidate.FilterURL(fullURL),
+	})
+}
+
+// CompareURLs returns diff events of every URL whose verdicts differ, in input order
+func (c *Comparator) CompareURLs(urls []string) []DiffEvent {
+	var events []DiffEvent
+	for _, fullURL := range urls {
+		if event, differs := c.Compare(fullURL); differs {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// diffEvent flags verdicts that differ in event and reports if any does
+func diffEvent(event DiffEvent) (DiffEvent, bool) {
+	if event.CurrentValid != event.CandidateValid {
+		event.Kind |= DiffValidate
+	}
+	if event.CurrentFiltered != event.CandidateFiltered {
+		event.Kind |= DiffFilter
+	}
+	return event, event.Kind != 0
+}
+
+// Dropped returns number of diff events suppressed by rate limit
+func (c *Comparator) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// sampled decides if live call is compared against candidate rules
+func (c *Comparator) sampled() bool {
+	if c.observer == nil || c.sampleRate <= 0 {
+		return false
+	}
+	return c.sampleRate >= 1 || rand.Float64() < c.sampleRate
+}
+
+// emit passes diff event to observer unless rate limit of current window is reached
+func (c *Comparator) emit(event DiffEvent) {
+	if !c.allow() {
+		c.dropped.Add(1)
+		return
+	}
+
+	defer func() {
+		_ = recover()
+	}()
+	c.observer(event)
+}
+
+// allow counts event in fixed rate limit window
+func (c *Comparator) allow() bool {
+	if c.limit <= 0 || c.interval <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.windowStart) >= c.interval {
+		c.windowStart = now
+		c.emitted = 0
+	}
+	if c.emitted >= c.limit {
+		return false
+	}
+	c.emitted++
+	return true
+}
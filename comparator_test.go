@@ -0,0 +1,188 @@
+package paramvalidator
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	comparatorCurrentRules   = "/list?page=[1,2,3]&sort=[asc,desc]"
+	comparatorCandidateRules = "/list?page=[1,2,3]&sort=[asc,desc,rank]"
+)
+
+// newTestComparator creates comparator of validators with comparator rules
+func newTestComparator(t *testing.T, observer DiffObserver, options ...ComparatorOption) *Comparator {
+	t.Helper()
+	c, err := NewComparator(newTestValidator(t, comparatorCurrentRules), newTestValidator(t, comparatorCandidateRules), observer, options...)
+	if err != nil {
+		t.Fatalf("Failed to create comparator: %v", err)
+	}
+	return c
+}
+
+func TestComparatorLive(t *testing.T) {
+	var events []DiffEvent
+	c := newTestComparator(t, func(event DiffEvent) {
+		events = append(events, event)
+	})
+
+	if !c.ValidateURL("/list?sort=asc") || len(events) != 0 {
+		t.Errorf("Expected agreeing verdict without events, got %+v", events)
+	}
+
+	if c.ValidateURL("/list?sort=rank") {
+		t.Error("Expected verdict of current rules")
+	}
+	expected := DiffEvent{URL: "/list?sort=rank", Kind: DiffValidate, CurrentValid: false, CandidateValid: true}
+	if len(events) != 1 || events[0] != expected {
+		t.Errorf("Events = %+v, expected %+v", events, expected)
+	}
+
+	events = nil
+	if result := c.FilterURL("/list?page=1&sort=rank"); result != "/list?page=1" {
+		t.Errorf("FilterURL = %q, expected output of current rules", result)
+	}
+	expected = DiffEvent{URL: "/list?page=1&sort=rank", Kind: DiffFilter, CurrentFiltered: "/list?page=1", CandidateFiltered: "/list?page=1&sort=rank"}
+	if len(events) != 1 || events[0] != expected {
+		t.Errorf("Events = %+v, expected %+v", events, expected)
+	}
+}
+
+func TestComparatorCompareURLs(t *testing.T) {
+	c := newTestComparator(t, nil)
+
+	events := c.CompareURLs([]string{
+		"/list?page=1",
+		"/list?sort=rank",
+		"/list?page=4",
+		"/list?page=2&sort=rank&debug=1",
+	})
+	if len(events) != 2 {
+		t.Fatalf("Expected two diff events, got %+v", events)
+	}
+	if events[0].URL != "/list?sort=rank" || events[0].Kind != DiffValidate|DiffFilter {
+		t.Errorf("Unexpected first event %+v", events[0])
+	}
+	if events[1].Kind != DiffFilter || events[1].CurrentFiltered != "/list?page=2" || events[1].CandidateFiltered != "/list?page=2&sort=rank" {
+		t.Errorf("Unexpected second event %+v", events[1])
+	}
+}
+
+func TestComparatorSamplingAndRateLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []ComparatorOption
+		events  int
+		dropped uint64
+	}{
+		{"all", nil, 5, 0},
+		{"sampling off", []ComparatorOption{WithDiffSampling(0)}, 0, 0},
+		{"rate limited", []ComparatorOption{WithDiffRateLimit(2, time.Hour)}, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := 0
+			c := newTestComparator(t, func(DiffEvent) { events++ }, tt.options...)
+			for range 5 {
+				c.ValidateURL("/list?sort=rank")
+			}
+			if events != tt.events || c.Dropped() != tt.dropped {
+				t.Errorf("events = %d, dropped = %d, expected %d and %d", events, c.Dropped(), tt.events, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestComparatorObserverPanic(t *testing.T) {
+	c := newTestComparator(t, func(DiffEvent) {
+		panic("observer failure")
+	})
+
+	if !c.ValidateURL("/list?page=1") || c.ValidateURL("/list?sort=rank") {
+		t.Error("Expected panicking observer not to affect verdicts")
+	}
+}
+
+func TestComparatorRequiresValidators(t *testing.T) {
+	if _, err := NewComparator(nil, nil, nil); err == nil {
+		t.Error("Expected error without validators")
+	}
+}
+
+func TestComparatorRateLimitWindow(t *testing.T) {
+	events := 0
+	c := newTestComparator(t, func(DiffEvent) { events++ }, WithDiffRateLimit(1, 20*time.Millisecond))
+
+	c.ValidateURL("/list?sort=rank")
+	c.ValidateURL("/list?sort=rank")
+	time.Sleep(30 * time.Millisecond)
+	// Next window passes events again, dropped count is kept
+	c.ValidateURL("/list?sort=rank")
+	if events != 2 || c.Dropped() != 1 {
+		t.Errorf("events = %d, dropped = %d, expected 2 and 1", events, c.Dropped())
+	}
+}
+
+func TestComparatorConcurrentRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	events := 0
+	c := newTestComparator(t, func(DiffEvent) {
+		mu.Lock()
+		events++
+		mu.Unlock()
+	}, WithDiffRateLimit(10, time.Hour))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				c.FilterURL("/list?sort=rank")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if events != 10 || c.Dropped() != 190 {
+		t.Errorf("events = %d, dropped = %d, expected 10 and 190", events, c.Dropped())
+	}
+}
+
+func TestComparatorOptionsDiffer(t *testing.T) {
+	// Same rules compiled with other options change filtering output only
+	rules := "/search?query|q=[*]&sort=[name,date]"
+	c, err := NewComparator(newTestValidator(t, rules), newTestValidator(t, rules, WithAliasRename(true)), nil)
+	if err != nil {
+		t.Fatalf("Failed to create comparator: %v", err)
+	}
+
+	event, differs := c.Compare("/search?q=shoes&sort=name")
+	if !differs || event.Kind != DiffFilter || event.CandidateFiltered != "/search?query=shoes&sort=name" {
+		t.Errorf("Compare = %+v, expected filtering difference only", event)
+	}
+	if _, differs := c.Compare("/search?query=shoes"); differs {
+		t.Error("Expected no difference for primary parameter name")
+	}
+}
+
+func TestComparatorCandidateReload(t *testing.T) {
+	candidate := newTestValidator(t, comparatorCandidateRules)
+	c, err := NewComparator(newTestValidator(t, comparatorCurrentRules), candidate, nil)
+	if err != nil {
+		t.Fatalf("Failed to create comparator: %v", err)
+	}
+
+	if _, differs := c.Compare("/list?sort=rank"); !differs {
+		t.Fatal("Expected candidate rules to differ")
+	}
+	// Comparator follows rules loaded into candidate later
+	if err := candidate.ParseRules(comparatorCurrentRules); err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+	if events := c.CompareURLs([]string{"/list?sort=rank", "/list?page=1"}); len(events) != 0 {
+		t.Errorf("Expected no differences after reload, got %+v", events)
+	}
+}
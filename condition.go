@@ -0,0 +1,194 @@
+// condition.go
+package paramvalidator
+
+import (
+	"sort"
+	"strings"
+)
+
+// compiledCondition is condition resolved to parameter indices
+type compiledCondition struct {
+	param     int
+	params    map[int]*ParamRule
+	values    discriminatorValues
+	condition Condition
+}
+
+// discriminatorValues holds values selecting condition or variant together with normalization of discriminator
+type discriminatorValues struct {
+	values     []string
+	transforms []ValueTransform
+	mappings   []ValueMapping
+	fold       bool
+}
+
+// String returns condition in rule syntax
+func (c Condition) String() string {
+	names := make([]string, 0, len(c.Params))
+	for name := range c.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rules := make([]string, len(names))
+	for i, name := range names {
+		rule := c.Params[name]
+		inverted := ""
+		if rule.Inverted {
+			inverted = "!"
+		}
+		rules[i] = name + "=" + inverted + "[" + rule.constraintString() + "]"
+	}
+	return "when(" + c.Param + "=" + strings.Join(c.Values, ",") + ") " + strings.Join(rules, "&")
+}
+
+// newDiscriminatorValues prepares values matched against discriminator declared by rule, nil rule when undeclared
+// Discriminator values are matched after transforms and mappings of discriminator rule and folded like its enum values
+func (rs *ruleSnapshot) newDiscriminatorValues(values []string, discriminator *ParamRule) discriminatorValues {
+	dv := discriminatorValues{values: values, fold: rs.foldsValues(discriminator)}
+	if discriminator != nil {
+		dv.transforms = discriminator.Transforms
+		dv.mappings = discriminator.Mappings
+		dv.values = transformValues(values, dv.transforms)
+	}
+	return dv
+}
+
+// matches checks if decoded discriminator value selects condition or variant
+func (dv *discriminatorValues) matches(value []byte) bool {
+	var transformBuf [64]byte
+	return matchesDiscriminator(dv.values, transformBytes(transformBuf[:0], value, dv.transforms), dv.mappings, dv.fold)
+}
+
+// compileCondition resolves condition parameter names to indices and indexes its rules
+func (rs *ruleSnapshot) compileCondition(condition Condition, discriminator *ParamRule) (compiledCondition, bool) {
+	param := rs.paramIndex.GetIndex(condition.Param)
+	if param == -1 {
+		return compiledCondition{}, false
+	}
+
+	compiled := compiledCondition{
+		param:     param,
+		params:    make(map[int]*ParamRule),
+		values:    rs.newDiscriminatorValues(condition.Values, discriminator),
+		condition: condition,
+	}
+	for name, rule := range condition.Params {
+		idx := rs.paramIndex.GetIndex(name)
+		if idx == -1 {
+			continue
+		}
+		ruleCopy := rs.copyParamRuleUnsafe(rule)
+		ruleCopy.BitmaskIndex = idx
+		compiled.params[idx] = ruleCopy
+		rs.addFoldedParamName(ruleCopy, idx)
+
+		rs.compiledRules.conditionedMask.SetBit(idx)
+	}
+
+	rs.compiledRules.discriminatorMask.SetBit(param)
+	rs.compiledRules.hasConditions = true
+	return compiled, true
+}
+
+// noteDiscriminator records span of first occurrence of discriminator parameter and marks repeated ones
+func (qt *queryTracker) noteDiscriminator(index, start, end int, discriminators ParamMask) {
+	if !discriminators.GetBit(index) {
+		return
+	}
+	if qt.state.discriminatorSpans[index].end != 0 {
+		qt.state.repeatedDiscriminators.SetBit(index)
+		return
+	}
+	qt.state.discriminatorSpans[index] = segmentSpan{start: uint16(start), end: uint16(end)}
+}
+
+// failedCondition returns condition rejecting accepted segment spanning query[start:end], nil if none
+// Condition applies while any occurrence of its discriminator holds one of condition values
+func (rs *ruleSnapshot) failedCondition(qt *queryTracker, check segmentCheck, urlPath string, start, end int) *compiledCondition {
+	if !qt.trackConditions || check.rule == nil || !rs.compiledRules.conditionedMask.GetBit(check.index) {
+		return nil
+	}
+
+	var failed *compiledCondition
+	rs.visitClauses(urlPath, func(clauses *compiledClauses, _ *URLRule) {
+		for i := range clauses.conditions {
+			condition := &clauses.conditions[i]
+			rule, exists := condition.params[check.index]
+			if !exists || failed != nil || !rs.discriminatorMatches(qt, condition) {
+				continue
+			}
+
+			var valueBuf [64]byte
+			value := rs.spanValue(qt, segmentSpan{start: uint16(start), end: uint16(end)}, valueBuf[:0])
+			if !rs.isValueValidBytesFast(rule, value) {
+				failed = condition
+			}
+		}
+	})
+	return failed
+}
+
+// conditionedRule returns rule of condition applying to accepted segment, or rule segment was checked with if none applies
+// Filtering writes value back and detailed result reports rewrite as applying condition normalizes it
+func (rs *ruleSnapshot) conditionedRule(qt *queryTracker, check segmentCheck, urlPath string) *ParamRule {
+	if !qt.trackConditions || !check.allowed || check.rule == nil || !rs.compiledRules.conditionedMask.GetBit(check.index) {
+		return check.rule
+	}
+
+	applied := check.rule
+	rs.visitClauses(urlPath, func(clauses *compiledClauses, _ *URLRule) {
+		for i := range clauses.conditions {
+			condition := &clauses.conditions[i]
+			rule, exists := condition.params[check.index]
+			if exists && applied == check.rule && rs.discriminatorMatches(qt, condition) {
+				applied = rule
+			}
+		}
+	})
+	return applied
+}
+
+// discriminatorMatches checks if any occurrence of discriminator of condition holds one of condition values
+// Checking every occurrence keeps repeated discriminator from smuggling parameter past condition
+func (rs *ruleSnapshot) discriminatorMatches(qt *queryTracker, condition *compiledCondition) bool {
+	span := qt.state.discriminatorSpans[condition.param]
+	for span.end != 0 {
+		var valueBuf [64]byte
+		if condition.values.matches(rs.spanValue(qt, span, valueBuf[:0])) {
+			return true
+		}
+		if !qt.state.repeatedDiscriminators.GetBit(condition.param) {
+			return false
+		}
+		span = rs.nextSegmentSpan(qt, condition.param, int(span.end)+1)
+	}
+	return false
+}
+
+// nextSegmentSpan returns span of next segment of parameter starting at query position from or later
+// Returned span is empty when parameter does not occur again
+func (rs *ruleSnapshot) nextSegmentSpan(qt *queryTracker, index, from int) segmentSpan {
+	start := from
+	if qt.queryBytes != nil {
+		for i := from; i <= len(qt.queryBytes); i++ {
+			if i == len(qt.queryBytes) || qt.queryBytes[i] == '&' {
+				if start < i && rs.segmentIndexBytes(qt.queryBytes[start:i], qt.state.active, qt.scratch) == index {
+					return segmentSpan{start: uint16(start), end: uint16(i)}
+				}
+				start = i + 1
+			}
+		}
+		return segmentSpan{}
+	}
+
+	for i := from; i <= len(qt.query); i++ {
+		if i == len(qt.query) || qt.query[i] == '&' {
+			if start < i && rs.segmentIndex(qt.query[start:i], qt.state.active) == index {
+				return segmentSpan{start: uint16(start), end: uint16(i)}
+			}
+			start = i + 1
+		}
+	}
+	return segmentSpan{}
+}
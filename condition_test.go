@@ -0,0 +1,149 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestConditionClauses(t *testing.T) {
+	rules := "/catalog?category=[shoes,books,toys]&subcategory=[*]&size=[*]; " +
+		"when(category=shoes) subcategory=[sneakers,boots]&size=[range:36..46]; " +
+		"when(category=books) subcategory=[fiction,poetry]"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"shoes branch", "/catalog?category=shoes&subcategory=boots", true},
+		{"books branch", "/catalog?category=books&subcategory=poetry", true},
+		{"value of other branch", "/catalog?category=books&subcategory=boots", false},
+		{"discriminator after dependent", "/catalog?subcategory=fiction&category=books", true},
+		{"discriminator after dependent mismatch", "/catalog?subcategory=fiction&category=shoes", false},
+		{"plugin constraint in branch", "/catalog?category=shoes&size=42", true},
+		{"plugin constraint in branch rejected", "/catalog?category=shoes&size=30", false},
+		{"branch does not apply to other values", "/catalog?category=books&size=30", true},
+		{"no branch for value", "/catalog?category=toys&subcategory=anything", true},
+		{"no discriminator", "/catalog?subcategory=anything", true},
+		{"repeated discriminator applies every branch", "/catalog?category=shoes&category=books&subcategory=boots", false},
+		{"repeated discriminator with same value", "/catalog?category=shoes&category=shoes&subcategory=boots", true},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewRangePlugin()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := tt.url[strings.Index(tt.url, "?")+1:]
+			if result := pv.ValidateQuery("/catalog", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/catalog"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConditionRepeatedDiscriminator(t *testing.T) {
+	tests := []struct {
+		rules string
+		query string
+	}{
+		{"/list?category=[shoes,books]&sub=[*]", "category=xxx&category=shoes&sub=fiction"},
+		{"/list?category=[shoes,books]&sub=[*]", "category=shoes&category=books&sub=sneakers"},
+		{"/list?category=[shoes,books]&sub=[*]", "category=books&sub=poetry&category=shoes"},
+		{"/list?category=[shoes,books]@first&sub=[*]", "category=books&category=shoes&sub=fiction"},
+		{"/list?category=[shoes,books]@last&sub=[*]", "category=books&category=shoes&sub=fiction"},
+	}
+	conditions := "; when(category=shoes) sub=[sneakers,boots]; when(category=books) sub=[fiction,poetry]"
+
+	for _, tt := range tests {
+		pv := newTestValidator(t, tt.rules+conditions)
+
+		// Filtered query must validate whichever occurrence of discriminator filtering keeps
+		filtered := pv.FilterQuery("/list", tt.query)
+		if !pv.ValidateQuery("/list", filtered) {
+			t.Errorf("%s: FilterQuery(%q) = %q, which does not validate", tt.rules, tt.query, filtered)
+		}
+		buffer := make([]byte, 0, len(tt.query))
+		filteredBytes := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), buffer)
+		if string(filteredBytes) != filtered || !pv.ValidateQueryBytes([]byte("/list"), filteredBytes) {
+			t.Errorf("%s: FilterQueryBytes(%q) = %q, expected valid %q", tt.rules, tt.query, filteredBytes, filtered)
+		}
+		if pv.ValidateQuery("/list", tt.query) {
+			t.Errorf("%s: ValidateQuery(%q) = true, expected false", tt.rules, tt.query)
+		}
+	}
+}
+
+func TestConditionMultipleValues(t *testing.T) {
+	pv := newTestValidator(t, "category=[*]&subcategory=[*]; when(category=shoes,boots) subcategory=[kids,adults]",
+		WithDecoding(DecodeQuery))
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/any?category=boots&subcategory=kids", true},
+		{"/any?category=%73hoes&subcategory=adults", true},
+		{"/any?category=shoes&subcategory=other", false},
+		{"/any?category=hats&subcategory=other", true},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestConditionDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/catalog?category=[shoes,books]&subcategory=[*]; "+
+		"when(category=shoes) subcategory=[sneakers,boots]")
+
+	result := pv.ValidateURLDetailed("/catalog?category=shoes&subcategory=poetry")
+	if len(result.Violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %+v", result.Violations)
+	}
+
+	violation := result.Violations[0]
+	if violation.Kind != ViolationCondition || violation.Param != "subcategory" || violation.Value != "poetry" ||
+		violation.Clause != "when(category=shoes) subcategory=[sneakers,boots]" || violation.URLPattern != "/catalog" {
+		t.Errorf("Unexpected condition violation: %+v", violation)
+	}
+}
+
+func TestConditionFilter(t *testing.T) {
+	pv := newTestValidator(t, "/catalog?category=[shoes,books]&subcategory=[*]&page=[*]; "+
+		"when(category=shoes) subcategory=[sneakers,boots]")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/catalog?category=shoes&subcategory=boots&page=2", "/catalog?category=shoes&subcategory=boots&page=2"},
+		{"/catalog?subcategory=poetry&category=shoes&page=2", "/catalog?category=shoes&page=2"},
+		{"/catalog?category=shoes&subcategory=poetry&subcategory=boots", "/catalog?category=shoes&subcategory=boots"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	query := []byte("category=shoes&subcategory=poetry")
+	buffer := make([]byte, 0, len(query))
+	if result := pv.FilterQueryBytes([]byte("/catalog"), query, buffer); string(result) != "category=shoes" {
+		t.Errorf("FilterQueryBytes = %q", result)
+	}
+}
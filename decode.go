@@ -0,0 +1,113 @@
+// decode.go
+package paramvalidator
+
+// DecodingMode controls decoding of query keys and values before rule matching
+type DecodingMode int
+
+const (
+	// DecodeNone compares raw query bytes (default)
+	DecodeNone DecodingMode = iota
+	// DecodePercent decodes percent escapes
+	DecodePercent
+	// DecodeQuery decodes percent escapes and '+' as space
+	DecodeQuery
+)
+
+// WithDecoding enables decoding of query keys and values before rule lookup and evaluation
+// Filtering still emits the original encoded segments
+func WithDecoding(mode DecodingMode) Option {
+	return func(pv *ParamValidator) {
+		pv.decodingMode = mode
+	}
+}
+
+// needsDecoding checks if component contains sequences decoded by mode
+func needsDecoding[T ~string | ~[]byte](mode DecodingMode, component T) bool {
+	if mode == DecodeNone {
+		return false
+	}
+	for i := 0; i < len(component); i++ {
+		if component[i] == '%' || (component[i] == '+' && mode == DecodeQuery) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendDecoded appends decoded component to dst
+// Returns false if component contains malformed percent escape
+func appendDecoded[T ~string | ~[]byte](dst []byte, component T, mode DecodingMode) ([]byte, bool) {
+	for i := 0; i < len(component); i++ {
+		switch c := component[i]; {
+		case c == '%':
+			if i+2 >= len(component) {
+				return dst, false
+			}
+			hi, okHi := unhex(component[i+1])
+			lo, okLo := unhex(component[i+2])
+			if !okHi || !okLo {
+				return dst, false
+			}
+			dst = append(dst, hi<<4|lo)
+			i += 2
+		case c == '+' && mode == DecodeQuery:
+			dst = append(dst, ' ')
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst, true
+}
+
+// unhex converts hex digit to its value
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// decodeKeyValue decodes key and value strings, allocating only when escapes are present
+func (rs *ruleSnapshot) decodeKeyValue(key, value string) (string, string, bool) {
+	if needsDecoding(rs.decodingMode, key) {
+		decoded, ok := appendDecoded(make([]byte, 0, len(key)), key, rs.decodingMode)
+		if !ok {
+			return key, value, false
+		}
+		key = string(decoded)
+	}
+	if needsDecoding(rs.decodingMode, value) {
+		decoded, ok := appendDecoded(make([]byte, 0, len(value)), value, rs.decodingMode)
+		if !ok {
+			return key, value, false
+		}
+		value = string(decoded)
+	}
+	return key, value, true
+}
+
+// decodeKeyValueBytes decodes key and value into scratch buffer
+// scratch is reused when it has sufficient capacity (at least len(key)+len(value))
+func (rs *ruleSnapshot) decodeKeyValueBytes(keyBytes, valueBytes, scratch []byte) ([]byte, []byte, bool) {
+	if !needsDecoding(rs.decodingMode, keyBytes) && !needsDecoding(rs.decodingMode, valueBytes) {
+		return keyBytes, valueBytes, true
+	}
+
+	buf, ok := appendDecoded(scratch[:0], keyBytes, rs.decodingMode)
+	if !ok {
+		return keyBytes, valueBytes, false
+	}
+	keyEnd := len(buf)
+
+	buf, ok = appendDecoded(buf, valueBytes, rs.decodingMode)
+	if !ok {
+		return keyBytes, valueBytes, false
+	}
+
+	return buf[:keyEnd:keyEnd], buf[keyEnd:], true
+}
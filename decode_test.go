@@ -0,0 +1,75 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestDecodingValidateURL(t *testing.T) {
+	rules := "sort=[date_desc,date_asc]&page=[range:1..10]&q=[hello world]"
+
+	tests := []struct {
+		name     string
+		mode     DecodingMode
+		url      string
+		expected bool
+	}{
+		{"encoded value without decoding", DecodeNone, "/search?sort=date%5Fdesc", false},
+		{"encoded value with percent decoding", DecodePercent, "/search?sort=date%5Fdesc", true},
+		{"encoded key with percent decoding", DecodePercent, "/search?%70age=5", true},
+		{"plus as space with query decoding", DecodeQuery, "/search?q=hello+world", true},
+		{"plus kept with percent decoding", DecodePercent, "/search?q=hello+world", false},
+		{"encoded space with percent decoding", DecodePercent, "/search?q=hello%20world", true},
+		{"malformed escape", DecodePercent, "/search?sort=date%5", false},
+		{"invalid hex escape", DecodePercent, "/search?sort=%zzdate", false},
+		{"plain value with decoding", DecodeQuery, "/search?sort=date_asc&page=3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, rules, WithPlugins(plugins.NewRangePlugin()), WithDecoding(tt.mode))
+
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			u := tt.url[len("/search?"):]
+			if result := pv.ValidateQueryBytes([]byte("/search"), []byte(u)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", u, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodingFilterKeepsEncoding(t *testing.T) {
+	pv := newTestValidator(t, "/search?sort=[date_desc]&q=[*]", WithDecoding(DecodeQuery))
+
+	filtered := pv.FilterURL("/search?sort=date%5Fdesc&q=a+b&x=1")
+	if expected := "/search?sort=date%5Fdesc&q=a+b"; filtered != expected {
+		t.Errorf("FilterURL = %q, expected %q", filtered, expected)
+	}
+
+	query := []byte("%73ort=date%5Fdesc&bad=%zz")
+	buffer := make([]byte, 0, len(query))
+	scratch := make([]byte, 0, len(query))
+	result := pv.FilterQueryBytesWithScratch([]byte("/search"), query, buffer, scratch)
+	if expected := "%73ort=date%5Fdesc"; string(result) != expected {
+		t.Errorf("FilterQueryBytesWithScratch = %q, expected %q", result, expected)
+	}
+}
+
+func TestDecodingDetailedInvalidEncoding(t *testing.T) {
+	pv := newTestValidator(t, "/search?sort=[date_desc]", WithDecoding(DecodePercent))
+
+	result := pv.ValidateQueryDetailed("/search", "sort=%G1")
+	if len(result.Violations) != 1 || result.Violations[0].Kind != ViolationInvalidEncoding {
+		t.Errorf("Expected invalid encoding violation, got %+v", result.Violations)
+	}
+	if result.Violations[0].Value != "%G1" {
+		t.Errorf("Expected raw value in violation, got %q", result.Violations[0].Value)
+	}
+}
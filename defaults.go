@@ -0,0 +1,264 @@
+// defaults.go
+package paramvalidator
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultsMode controls how NormalizeURL treats parameters declaring default values
+type DefaultsMode int
+
+const (
+	// DefaultsFill adds missing parameters with their default values (default)
+	DefaultsFill DefaultsMode = iota
+	// DefaultsStrip removes parameters whose value equals the default
+	DefaultsStrip
+)
+
+// WithDefaultsMode sets how NormalizeURL applies default values
+func WithDefaultsMode(mode DefaultsMode) Option {
+	return func(pv *ParamValidator) {
+		pv.defaultsMode = mode
+	}
+}
+
+// checkDefaultValue checks that default value satisfies parameter rule
+// Callback rules are resolved at runtime and cannot be checked in advance
+func checkDefaultValue(rule *ParamRule) error {
+	if isParamNamePattern(rule.Name) {
+		return fmt.Errorf("pattern parameter '%s' cannot have default value", rule.Name)
+	}
+	if strings.ContainsAny(rule.Default, " \t") {
+		return fmt.Errorf("default value '%s' of parameter '%s' cannot contain spaces", rule.Default, rule.Name)
+	}
+
+	valid := true
+	switch rule.Pattern {
+	case PatternKeyOnly:
+		return fmt.Errorf("key-only parameter '%s' cannot have default value", rule.Name)
+	case PatternEnum:
+		valid = containsValue(rule.Values, rule.transformValue(rule.Default), rule.CaseInsensitive)
+	case "plugin":
+		valid = rule.CustomValidator != nil && rule.CustomValidator(rule.transformValue(rule.Default))
+	}
+	if rule.Inverted {
+		valid = !valid
+	}
+
+	if !valid {
+		return fmt.Errorf("default value '%s' does not satisfy rule for parameter '%s'", rule.Default, rule.Name)
+	}
+	return nil
+}
+
+// checkDefaultSpaces rejects spaces inside default values of URL rule, which would be dropped with other spaces
+func checkDefaultSpaces(ruleStr string) error {
+	depth := 0
+	// Brackets after '=' hold constraint, others belong to parameter name
+	constraint, constrained := false, false
+	for i := 0; i < len(ruleStr); i++ {
+		switch c := ruleStr[i]; {
+		case c == '[':
+			if depth == 0 {
+				constraint = strings.HasSuffix(strings.TrimRight(ruleStr[:i], " \t!"), "=")
+			}
+			depth++
+		case c == ']':
+			if depth > 0 {
+				depth--
+			}
+			constrained = depth == 0 && constraint
+		case depth > 0:
+		case c == '"':
+			// Quoted names may contain brackets
+			if end := strings.IndexByte(ruleStr[i+1:], '"'); end != -1 {
+				i += end + 1
+			}
+		case c == '&' || c == '?':
+			constrained = false
+		case c == '=' && constrained:
+			end := strings.IndexByte(ruleStr[i:], '&')
+			if end == -1 {
+				end = len(ruleStr) - i
+			}
+			if value := strings.TrimSpace(ruleStr[i+1 : i+end]); strings.ContainsAny(value, " \t") {
+				return fmt.Errorf("default value '%s' cannot contain spaces", value)
+			}
+			i += end - 1
+			constrained = false
+		}
+	}
+	return nil
+}
+
+// addDefaultParam registers parameter index whose rule declares default value
+func (cr *CompiledRules) addDefaultParam(idx int) {
+	if !slices.Contains(cr.defaultParams, idx) {
+		cr.defaultParams = append(cr.defaultParams, idx)
+	}
+	cr.hasDefaults = true
+}
+
+// sortDefaultParams orders default parameters by name so filled queries are stable across rule reloads
+func (cr *CompiledRules) sortDefaultParams() {
+	sort.Slice(cr.defaultParams, func(i, j int) bool {
+		return cr.paramIndex.GetParamName(cr.defaultParams[i]) < cr.paramIndex.GetParamName(cr.defaultParams[j])
+	})
+}
+
+// NormalizeURL filters URL like FilterURL and applies default values of parameters
+// Missing or invalid parameters get their defaults, or with DefaultsStrip parameters equal
+// to their defaults are removed, so equivalent URLs normalize to the same string
+// In report-only mode URL that normalization would change is reported and returned unchanged
+func (pv *ParamValidator) NormalizeURL(fullURL string) string {
+	if !pv.initialized.Load() {
+		return fullURL
+	}
+	rs := pv.current()
+	normalized := rs.normalizeURL(fullURL)
+	if rs.reportObserver == nil || normalized == fullURL {
+		return normalized
+	}
+	rs.reportURL(ReportNormalize, fullURL, normalized)
+	return fullURL
+}
+
+// normalizeURL filters URL and applies default values with rules of snapshot
+func (rs *ruleSnapshot) normalizeURL(fullURL string) string {
+	if fullURL == "" {
+		return fullURL
+	}
+
+	if len(fullURL) > MaxURLLength {
+		return fullURL
+	}
+
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return fullURL
+	}
+
+	if rs.compiledRules == nil || !rs.compiledRules.hasDefaults {
+		return rs.normalizeURLFast(u)
+	}
+
+	// Filled query is checked again when filtered, so filling must not notify observers
+	u.RawQuery = rs.withoutObservers().fillDefaults(u.RawQuery, u.Path)
+	normalized := rs.normalizeURLFast(u)
+	if rs.defaultsMode == DefaultsStrip {
+		normalized = rs.stripDefaults(normalized, u.Path)
+	}
+	return normalized
+}
+
+// fillDefaults replaces invalid segments of parameters with defaults and appends defaults of missing ones
+func (rs *ruleSnapshot) fillDefaults(queryString, urlPath string) string {
+	masks := rs.getParamMasksForURL(urlPath)
+	rs.selectVariant(&masks, urlPath, queryString)
+
+	var builder strings.Builder
+	valid := NewParamMask()
+	appendSegment := func(segment string) {
+		if builder.Len() > 0 {
+			builder.WriteByte('&')
+		}
+		builder.WriteString(segment)
+	}
+
+	start := 0
+	for i := 0; i <= len(queryString); i++ {
+		if i == len(queryString) || queryString[i] == '&' {
+			if start < i {
+				segment := queryString[start:i]
+				check := rs.checkSegment(segment, masks, urlPath)
+				if rewritten, ok := appendRewrittenSegment(rs, nil, segment, check); ok {
+					segment, check.allowed = string(rewritten), true
+				}
+				switch {
+				case check.allowed:
+					valid.SetBit(check.index)
+					appendSegment(segment)
+				case check.rule == nil || !check.rule.HasDefault:
+					appendSegment(segment)
+				}
+			}
+			start = i + 1
+		}
+	}
+
+	active := masks.CombinedMask()
+	for _, idx := range rs.compiledRules.defaultParams {
+		if !active.GetBit(idx) || valid.GetBit(idx) {
+			continue
+		}
+		if rule := rs.findParamRuleByIndex(idx, masks, urlPath); rule != nil && rule.HasDefault {
+			appendSegment(string(rs.appendDefaultSegment(nil, rule)))
+		}
+	}
+
+	return builder.String()
+}
+
+// appendDefaultSegment appends parameter with its default value encoded for query
+// Without decoding rules match raw bytes, so only separators ending segment are encoded
+func (rs *ruleSnapshot) appendDefaultSegment(dst []byte, rule *ParamRule) []byte {
+	dst = rs.appendDefaultComponent(dst, rule.Name)
+	dst = append(dst, '=')
+	return rs.appendDefaultComponent(dst, rule.transformValue(rule.Default))
+}
+
+// appendDefaultComponent appends name or value of default segment encoded for query
+func (rs *ruleSnapshot) appendDefaultComponent(dst []byte, component string) []byte {
+	for i := 0; i < len(component); i++ {
+		if c := component[i]; rs.decodingMode == DecodeNone && c != '&' && c != '#' {
+			dst = append(dst, c)
+		} else {
+			dst = appendEscapedByte(dst, c)
+		}
+	}
+	return dst
+}
+
+// stripDefaults removes segments of normalized URL whose value equals default of their rule
+func (rs *ruleSnapshot) stripDefaults(normalized, urlPath string) string {
+	queryStart := strings.IndexByte(normalized, '?')
+	if queryStart == -1 {
+		return normalized
+	}
+
+	queryString, fragment := normalized[queryStart+1:], ""
+	if i := strings.IndexByte(queryString, '#'); i != -1 {
+		queryString, fragment = queryString[:i], queryString[i:]
+	}
+
+	masks := rs.getParamMasksForURL(urlPath)
+	rs.selectVariant(&masks, urlPath, queryString)
+	active := masks.CombinedMask()
+
+	kept := make([]byte, 0, len(queryString))
+	start := 0
+	for i := 0; i <= len(queryString); i++ {
+		if i == len(queryString) || queryString[i] == '&' {
+			if start < i {
+				segment := queryString[start:i]
+				rule := rs.findParamRuleByIndex(rs.segmentIndex(segment, active), masks, urlPath)
+				if rule == nil || !rule.HasDefault || string(appendSegmentValue(nil, segment, rs.decodingMode)) != rule.transformValue(rule.Default) {
+					if len(kept) > 0 {
+						kept = append(kept, '&')
+					}
+					kept = append(kept, segment...)
+				}
+			}
+			start = i + 1
+		}
+	}
+
+	if len(kept) == 0 {
+		return normalized[:queryStart] + fragment
+	}
+	return normalized[:queryStart+1] + string(kept) + fragment
+}
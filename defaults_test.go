@@ -0,0 +1,105 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestNormalizeURLFillDefaults(t *testing.T) {
+	pv := newTestValidator(t, "/list?page=[range:1..100]=1&per_page=[10,20,50]=20&sort=[name,date]; lang=[en,de]=en",
+		WithPlugins(plugins.NewRangePlugin()))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/list", "/list?lang=en&page=1&per_page=20"},
+		{"/list?page=1&per_page=20&lang=en", "/list?page=1&per_page=20&lang=en"},
+		{"/list?sort=name", "/list?sort=name&lang=en&page=1&per_page=20"},
+		{"/list?page=500&per_page=50", "/list?per_page=50&lang=en&page=1"},
+		{"/list?page=3&debug=1", "/list?page=3&lang=en&per_page=20"},
+		{"/other?lang=de", "/other?lang=de"},
+		{"/other", "/other?lang=en"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.NormalizeURL(tt.url); result != tt.expected {
+			t.Errorf("NormalizeURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	// Defaults never change validation or filtering
+	if pv.ValidateURL("/list?page=500") {
+		t.Error("Expected invalid page to stay invalid")
+	}
+	if result := pv.FilterURL("/list?sort=name"); result != "/list?sort=name" {
+		t.Errorf("FilterURL = %q, expected defaults not to be added", result)
+	}
+}
+
+func TestNormalizeURLStripDefaults(t *testing.T) {
+	pv := newTestValidator(t, "/list?page=[range:1..100]=1&per_page=[10,20,50]=20&sort=[name,date]",
+		WithPlugins(plugins.NewRangePlugin()), WithDefaultsMode(DefaultsStrip), WithDecoding(DecodeQuery))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/list", "/list"},
+		{"/list?page=1&per_page=20", "/list"},
+		{"/list?per_page=%32%30&sort=date", "/list?sort=date"},
+		{"/list?page=2&per_page=20", "/list?page=2"},
+		{"/list?page=0", "/list"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.NormalizeURL(tt.url); result != tt.expected {
+			t.Errorf("NormalizeURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeURLRequiredDefault(t *testing.T) {
+	pv := newTestValidator(t, "/items?+page=[1,2,3]=1&q=[*]")
+
+	if result := pv.NormalizeURL("/items?q=x"); result != "/items?q=x&page=1" {
+		t.Errorf("NormalizeURL = %q, expected required default filled", result)
+	}
+	if result := pv.FilterURL("/items?q=x"); result != "" {
+		t.Errorf("FilterURL = %q, expected missing required param to reject", result)
+	}
+}
+
+func TestNormalizeURLWithoutDefaults(t *testing.T) {
+	pv := newTestValidator(t, "/list?page=[1,2]")
+
+	for _, url := range []string{"/list?page=1&x=2", "/list", "/list?page=5"} {
+		if normalized, filtered := pv.NormalizeURL(url), pv.FilterURL(url); normalized != filtered {
+			t.Errorf("NormalizeURL(%q) = %q, expected FilterURL result %q", url, normalized, filtered)
+		}
+	}
+}
+
+func TestNormalizeURLEncodesDefaults(t *testing.T) {
+	tests := []struct {
+		mode     DecodingMode
+		expected string
+	}{
+		{DecodeQuery, "/s?q=a%2Bb&u=%C3%BC"},
+		{DecodePercent, "/s?q=a%2Bb&u=%C3%BC"},
+		{DecodeNone, "/s?q=a+b&u=\u00fc"},
+	}
+
+	for _, tt := range tests {
+		pv := newTestValidator(t, "/s?q=[a+b,c]=a+b&u=[\u00fc,c]=\u00fc", WithDecoding(tt.mode))
+		result := pv.NormalizeURL("/s")
+		if result != tt.expected {
+			t.Errorf("NormalizeURL with mode %d = %q, expected %q", tt.mode, result, tt.expected)
+		}
+		// Filled defaults decode back to values their rules accept
+		if !pv.ValidateURL(result) {
+			t.Errorf("ValidateURL(%q) with mode %d = false, expected filled defaults to be valid", result, tt.mode)
+		}
+	}
+}
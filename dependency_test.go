@@ -0,0 +1,113 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDependencyClauses(t *testing.T) {
+	rules := "/list?sort=[name,date]&order=[asc,desc]&page=[*]&per_page=[10,50]; requires sort->order; requires page->per_page"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"dependent with prerequisite", "/list?sort=name&order=asc", true},
+		{"prerequisite alone", "/list?order=asc", true},
+		{"dependent without prerequisite", "/list?sort=name", false},
+		{"second dependency unmet", "/list?sort=name&order=asc&page=2", false},
+		{"prerequisite order does not matter", "/list?per_page=10&page=2", true},
+		{"invalid prerequisite", "/list?sort=name&order=up", false},
+		{"no params", "/list", true},
+	}
+
+	pv := newTestValidator(t, rules)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := ""
+			if i := strings.Index(tt.url, "?"); i != -1 {
+				query = tt.url[i+1:]
+			}
+			if result := pv.ValidateQuery("/list", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/list"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDependencyFilter(t *testing.T) {
+	pv := newTestValidator(t, "/list?a=[*]&b=[*]&c=[1,2]&q=[*]; requires a->b; requires b->c")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/list?a=1&b=2&c=1", "/list?a=1&b=2&c=1"},
+		{"/list?a=1&b=2&c=9&q=x", "/list?q=x"},
+		{"/list?q=x&a=1&c=2", "/list?q=x&c=2"},
+		{"/list?a=1", "/list"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+
+		query := tt.url[strings.Index(tt.url, "?")+1:]
+		expectedQuery := ""
+		if i := strings.Index(tt.expected, "?"); i != -1 {
+			expectedQuery = tt.expected[i+1:]
+		}
+		if result := pv.FilterQuery("/list", query); result != expectedQuery {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", query, result, expectedQuery)
+		}
+		buffer := make([]byte, 0, len(query))
+		if result := pv.FilterQueryBytes([]byte("/list"), []byte(query), buffer); string(result) != expectedQuery {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", query, result, expectedQuery)
+		}
+	}
+}
+
+func TestDependencyGlobalClauses(t *testing.T) {
+	pv := newTestValidator(t, "from=[*]&to=[*]\nrequires from->to\nrequires to->from")
+
+	if !pv.ValidateURL("/any?from=1&to=2") {
+		t.Error("Expected both parameters to be valid")
+	}
+	if pv.ValidateURL("/any?to=2") {
+		t.Error("Expected missing prerequisite to be rejected")
+	}
+
+	result := pv.ValidateURLDetailed("/any?to=2")
+	if len(result.Violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %+v", result.Violations)
+	}
+	violation := result.Violations[0]
+	if violation.Kind != ViolationDependency || violation.Param != "to" || violation.Value != "from" ||
+		violation.Clause != "requires to->from" || violation.Source != SourceGlobal {
+		t.Errorf("Unexpected violation %+v", violation)
+	}
+}
+
+func TestDependencyAllowAll(t *testing.T) {
+	pv := newTestValidator(t, "/open?*; requires a->b;a=[*];b=[*]")
+
+	if pv.ValidateURL("/open?a=1&x=2") {
+		t.Error("Expected dependency to apply to allow-all rule")
+	}
+	if result := pv.FilterURL("/open?a=1&x=2"); result != "/open?x=2" {
+		t.Errorf("FilterURL = %q, expected %q", result, "/open?x=2")
+	}
+}
@@ -0,0 +1,87 @@
+// deprecation.go
+package paramvalidator
+
+import (
+	"strings"
+)
+
+// WarningKind classifies warning about parameter that does not affect verdict
+type WarningKind int
+
+const (
+	// WarningDeprecated reports parameter whose rule is marked with ~
+	WarningDeprecated WarningKind = iota
+)
+
+// String returns human readable name of warning kind
+func (wk WarningKind) String() string {
+	switch wk {
+	case WarningDeprecated:
+		return "deprecated param"
+	default:
+		return "unknown"
+	}
+}
+
+// Warning describes parameter reported without affecting verdict
+type Warning struct {
+	Kind       WarningKind
+	Param      string
+	Value      string
+	URLPattern string
+	Source     RuleSource
+}
+
+// WarningObserver receives warnings raised while validating or filtering query of URL path
+// Loading rules from observer affects only calls started after it
+type WarningObserver func(urlPath string, warning Warning)
+
+// WithWarningObserver sets observer notified whenever query holds deprecated parameter
+func WithWarningObserver(observer WarningObserver) Option {
+	return func(pv *ParamValidator) {
+		pv.warningObserver = observer
+	}
+}
+
+// observeDeprecated passes deprecated parameter of checked segment to warning observer
+// Key and value are copied only when rule is deprecated and observer is set
+func observeDeprecated[T ~string | ~[]byte](rs *ruleSnapshot, key, value T, check segmentCheck, masks ParamMasks, urlPath string) {
+	if rs.warningObserver == nil || check.rule == nil || !check.rule.Deprecated {
+		return
+	}
+	rs.safeObserve(urlPath, rs.deprecationWarning(string(key), string(value), check.index, masks, urlPath))
+}
+
+// collectDeprecated records deprecated parameter of checked segment in detailed result and notifies observer
+func (rs *ruleSnapshot) collectDeprecated(result *ValidationResult, key, value string, check segmentCheck, masks ParamMasks, urlPath string) {
+	if check.rule == nil || !check.rule.Deprecated {
+		return
+	}
+	if decodedKey, decodedValue, ok := rs.decodeKeyValue(key, value); ok {
+		key, value = decodedKey, decodedValue
+	}
+
+	warning := rs.deprecationWarning(key, value, check.index, masks, urlPath)
+	result.Warnings = append(result.Warnings, warning)
+	if rs.warningObserver != nil {
+		rs.safeObserve(urlPath, warning)
+	}
+}
+
+// deprecationWarning builds warning for deprecated parameter with its rule source
+func (rs *ruleSnapshot) deprecationWarning(key, value string, index int, masks ParamMasks, urlPath string) Warning {
+	warning := Warning{Kind: WarningDeprecated, Param: key, Value: value, Source: masks.GetRuleSource(index)}
+	if _, urlRule := rs.resolveParamRuleByIndex(index, masks, urlPath); urlRule != nil {
+		warning.URLPattern = urlRule.URLPattern
+	}
+	return warning
+}
+
+// safeObserve executes warning observer with panic protection
+// URL path is cloned so that path converted from []byte on zero-alloc paths stays on stack
+func (rs *ruleSnapshot) safeObserve(urlPath string, warning Warning) {
+	defer func() {
+		_ = recover()
+	}()
+	rs.warningObserver(strings.Clone(urlPath), warning)
+}
@@ -0,0 +1,139 @@
+package paramvalidator
+
+import (
+	"testing"
+)
+
+const deprecationRules = "~old_filter=[*]; /list?page=[*]&~legacy=[a,b]&~mode=![debug]"
+
+func TestDeprecatedParamsValidate(t *testing.T) {
+	pv := newTestValidator(t, deprecationRules)
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"old_filter=x&page=1", true},
+		{"legacy=a", true},
+		{"legacy=c", false},
+		{"mode=fast", true},
+		{"mode=debug", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL("/list?" + tt.query); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQueryBytes([]byte("/list"), []byte(tt.query)); result != tt.expected {
+			t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+	}
+}
+
+func TestDeprecatedParamsDetailed(t *testing.T) {
+	pv := newTestValidator(t, deprecationRules, WithDecoding(DecodeQuery))
+
+	result := pv.ValidateURLDetailed("/list?page=1&old%5Ffilter=x&legacy=b")
+	if !result.Valid {
+		t.Fatalf("Expected valid result, got %+v", result.Violations)
+	}
+
+	expected := []Warning{
+		{Kind: WarningDeprecated, Param: "old_filter", Value: "x", Source: SourceGlobal},
+		{Kind: WarningDeprecated, Param: "legacy", Value: "b", URLPattern: "/list", Source: SourceSpecificURL},
+	}
+	if len(result.Warnings) != len(expected) {
+		t.Fatalf("Warnings = %+v, expected %+v", result.Warnings, expected)
+	}
+	for i := range expected {
+		if result.Warnings[i] != expected[i] {
+			t.Errorf("Warnings[%d] = %+v, expected %+v", i, result.Warnings[i], expected[i])
+		}
+	}
+
+	if result := pv.ValidateURLDetailed("/list?mode=debug"); result.Valid || len(result.Warnings) != 1 {
+		t.Errorf("Expected rejected inverted deprecated param to be reported, got %+v", result)
+	}
+}
+
+func TestDeprecatedParamsObserver(t *testing.T) {
+	var warnings []Warning
+	var paths []string
+	pv := newTestValidator(t, deprecationRules, WithWarningObserver(func(urlPath string, warning Warning) {
+		paths = append(paths, urlPath)
+		warnings = append(warnings, warning)
+	}))
+
+	calls := []struct {
+		name string
+		call func()
+	}{
+		{"ValidateURL", func() { pv.ValidateURL("/list?legacy=a&page=1") }},
+		{"ValidateQuery", func() { pv.ValidateQuery("/list", "legacy=a&page=1") }},
+		{"ValidateQueryBytes", func() { pv.ValidateQueryBytes([]byte("/list"), []byte("legacy=a&page=1")) }},
+		{"ValidateURLDetailed", func() { pv.ValidateURLDetailed("/list?legacy=a&page=1") }},
+		{"FilterURL", func() { pv.FilterURL("/list?legacy=a&page=1") }},
+		{"FilterQueryBytes", func() { pv.FilterQueryBytes([]byte("/list"), []byte("legacy=a&page=1"), make([]byte, 0, 64)) }},
+	}
+
+	for _, tc := range calls {
+		warnings, paths = nil, nil
+		tc.call()
+		if len(warnings) != 1 {
+			t.Errorf("%s: observed %d warnings, expected 1", tc.name, len(warnings))
+			continue
+		}
+		if warnings[0].Param != "legacy" || warnings[0].Value != "a" || warnings[0].URLPattern != "/list" || paths[0] != "/list" {
+			t.Errorf("%s: observed %+v for %q", tc.name, warnings[0], paths[0])
+		}
+	}
+
+	warnings = nil
+	pv.ValidateURL("/list?page=1")
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings without deprecated params, got %+v", warnings)
+	}
+}
+
+func TestDeprecatedParamsNormalize(t *testing.T) {
+	var warnings []Warning
+	var reports []Report
+	observer := WithWarningObserver(func(_ string, warning Warning) {
+		warnings = append(warnings, warning)
+	})
+	rules := "/list?page=[*]&~legacy=[a,b]&sort=[asc,desc]=asc"
+
+	tests := []struct {
+		name     string
+		options  []Option
+		url      string
+		expected string
+	}{
+		{"defaults filled", []Option{observer}, "/list?legacy=a&page=1", "/list?legacy=a&page=1&sort=asc"},
+		{"invalid value replaced", []Option{observer}, "/list?legacy=a&sort=up", "/list?legacy=a&sort=asc"},
+		{"report-only", []Option{observer, collectReports(&reports)}, "/list?legacy=a&debug=1", "/list?legacy=a&debug=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, rules, tt.options...)
+			warnings, reports = nil, nil
+			if result := pv.NormalizeURL(tt.url); result != tt.expected {
+				t.Errorf("NormalizeURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+			}
+			if len(warnings) != 1 || warnings[0].Param != "legacy" {
+				t.Errorf("Observed warnings %+v, expected legacy once", warnings)
+			}
+		})
+	}
+}
+
+func TestDeprecatedParamsObserverPanic(t *testing.T) {
+	pv := newTestValidator(t, deprecationRules, WithWarningObserver(func(string, Warning) {
+		panic("observer failure")
+	}))
+
+	if !pv.ValidateURL("/list?legacy=a") {
+		t.Error("Expected panicking observer not to affect verdict")
+	}
+}
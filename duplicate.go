@@ -0,0 +1,271 @@
+// duplicate.go
+package paramvalidator
+
+// DuplicatePolicy controls handling of parameters repeated within single query
+type DuplicatePolicy int
+
+const (
+	// DuplicateDefault inherits validator policy (allow when not configured)
+	DuplicateDefault DuplicatePolicy = iota
+	// DuplicateAllow validates every occurrence independently
+	DuplicateAllow
+	// DuplicateReject treats repeated parameter as violation
+	DuplicateReject
+	// DuplicateKeepFirst keeps only the first occurrence, other occurrences must still hold valid values
+	DuplicateKeepFirst
+	// DuplicateKeepLast keeps only the last occurrence, other occurrences must still hold valid values
+	DuplicateKeepLast
+)
+
+// String returns rule syntax name of duplicate policy
+func (dp DuplicatePolicy) String() string {
+	switch dp {
+	case DuplicateAllow:
+		return "allow"
+	case DuplicateReject:
+		return "reject"
+	case DuplicateKeepFirst:
+		return "first"
+	case DuplicateKeepLast:
+		return "last"
+	default:
+		return "default"
+	}
+}
+
+// WithDuplicatePolicy sets policy for repeated parameters without explicit policy in rules
+// Rules with occurrence counts are governed by their counts instead
+func WithDuplicatePolicy(policy DuplicatePolicy) Option {
+	return func(pv *ParamValidator) {
+		pv.duplicatePolicy = policy
+	}
+}
+
+// parseDuplicatePolicy converts rule suffix name to duplicate policy
+func parseDuplicatePolicy(name string) (DuplicatePolicy, bool) {
+	switch name {
+	case "allow":
+		return DuplicateAllow, true
+	case "reject":
+		return DuplicateReject, true
+	case "first":
+		return DuplicateKeepFirst, true
+	case "last":
+		return DuplicateKeepLast, true
+	default:
+		return DuplicateDefault, false
+	}
+}
+
+// effectiveDuplicatePolicy returns policy applied to rule
+func (rs *ruleSnapshot) effectiveDuplicatePolicy(rule *ParamRule) DuplicatePolicy {
+	if rule.DuplicatePolicy != DuplicateDefault {
+		return rule.DuplicatePolicy
+	}
+	if rule.MinOccurs > 0 || rule.MaxOccurs > 0 {
+		return DuplicateAllow
+	}
+	return rs.duplicatePolicy
+}
+
+// segmentAdmission is decision about single query segment
+type segmentAdmission int
+
+const (
+	admitAccept segmentAdmission = iota
+	admitIgnore
+	admitReject
+)
+
+// queryTracker holds per-query state shared by validation and filtering passes
+// Per-parameter counters and spans live in state, attached only when compiled rules need them
+type queryTracker struct {
+	present          ParamMask
+	countOccurrences bool
+	trackDuplicates  bool
+	trackOrder       bool
+	trackValues      bool
+	trackConditions  bool
+	order            uint16
+	query            string
+	queryBytes       []byte
+	scratch          []byte
+	state            *trackerState
+}
+
+// trackerState holds per-parameter counters and spans of single query
+type trackerState struct {
+	occurrences        occurrenceCounter
+	totals             occurrenceCounter
+	seen               occurrenceCounter
+	firstSeen          [MaxParamsCount]uint16
+	spans              [MaxParamsCount]segmentSpan
+	discriminatorSpans [MaxParamsCount]segmentSpan
+	// repeatedDiscriminators marks discriminators occurring more than once, active resolves their later occurrences
+	repeatedDiscriminators ParamMask
+	active                 ParamMask
+}
+
+// initQueryTracker prepares tracker for query string
+// Occurrence totals and discriminators are collected upfront only when duplicate policies or conditions are in effect
+func (rs *ruleSnapshot) initQueryTracker(qt *queryTracker, queryString string, masks ParamMasks) {
+	qt.query = queryString
+	if !rs.enableTracking(qt) {
+		return
+	}
+
+	active := masks.CombinedMask()
+	qt.state.active = active
+	start := 0
+	for i := 0; i <= len(queryString); i++ {
+		if i == len(queryString) || queryString[i] == '&' {
+			if start < i {
+				idx := rs.segmentIndex(queryString[start:i], active)
+				qt.state.totals.increment(idx)
+				qt.noteDiscriminator(idx, start, i, rs.compiledRules.discriminatorMask)
+			}
+			start = i + 1
+		}
+	}
+}
+
+// initQueryTrackerBytes prepares tracker for query in []byte form without allocations
+func (rs *ruleSnapshot) initQueryTrackerBytes(qt *queryTracker, queryBytes []byte, masks ParamMasks, scratch []byte) {
+	qt.queryBytes = queryBytes
+	qt.scratch = scratch
+	if !rs.enableTracking(qt) {
+		return
+	}
+
+	active := masks.CombinedMask()
+	qt.state.active = active
+	start := 0
+	for i := 0; i <= len(queryBytes); i++ {
+		if i == len(queryBytes) || queryBytes[i] == '&' {
+			if start < i {
+				idx := rs.segmentIndexBytes(queryBytes[start:i], active, scratch)
+				qt.state.totals.increment(idx)
+				qt.noteDiscriminator(idx, start, i, rs.compiledRules.discriminatorMask)
+			}
+			start = i + 1
+		}
+	}
+}
+
+// enableTracking turns on tracking required by compiled rules, reporting whether totals and discriminators must be collected
+// Tracker without attached state tracks presence only
+func (rs *ruleSnapshot) enableTracking(qt *queryTracker) bool {
+	if qt.state == nil {
+		return false
+	}
+	qt.countOccurrences = rs.compiledRules.hasOccurrences
+	qt.trackDuplicates = rs.compiledRules.hasDuplicatePolicy
+	qt.trackOrder = rs.compiledRules.hasClauses
+	qt.trackValues = rs.compiledRules.hasAssertions
+	qt.trackConditions = rs.compiledRules.hasConditions
+	return qt.trackDuplicates || qt.trackConditions
+}
+
+// admitSegment decides whether checked segment spanning query[start:end] is accepted, ignored or rejected
+// Returned violation kind is ViolationNone when rejection comes from the value check itself
+func (rs *ruleSnapshot) admitSegment(qt *queryTracker, check segmentCheck, urlPath string, start, end int) (segmentAdmission, ViolationKind) {
+	if qt.trackDuplicates && check.rule != nil {
+		seen := qt.state.seen.increment(check.index)
+		switch rs.effectiveDuplicatePolicy(check.rule) {
+		case DuplicateReject:
+			if qt.state.totals.count(check.index) > 1 {
+				return admitReject, ViolationDuplicate
+			}
+		case DuplicateKeepFirst:
+			if seen > 1 {
+				return ignoredAdmission(check), ViolationNone
+			}
+		case DuplicateKeepLast:
+			if seen < qt.state.totals.count(check.index) {
+				return ignoredAdmission(check), ViolationNone
+			}
+		}
+	}
+
+	if !check.allowed {
+		return admitReject, ViolationNone
+	}
+	if qt.trackConditions && rs.failedCondition(qt, check, urlPath, start, end) != nil {
+		return admitReject, ViolationCondition
+	}
+	if qt.countOccurrences && !qt.state.occurrences.add(check) {
+		return admitReject, ViolationOccurrences
+	}
+	qt.markPresent(check.index, start, end)
+	return admitAccept, ViolationNone
+}
+
+// ignoredAdmission decides occurrence duplicate policy ignores
+// Ignored occurrence is still value-checked, so validation rejects query carrying invalid duplicate
+// while filtering drops it either way
+func ignoredAdmission(check segmentCheck) segmentAdmission {
+	if !check.allowed {
+		return admitReject
+	}
+	return admitIgnore
+}
+
+// markPresent records accepted parameter together with order and span of its first occurrence
+func (qt *queryTracker) markPresent(index, start, end int) {
+	qt.present.SetBit(index)
+	if index < 0 || index >= MaxParamsCount {
+		return
+	}
+	if qt.trackValues && qt.state.spans[index].end == 0 {
+		qt.state.spans[index] = segmentSpan{start: uint16(start), end: uint16(end)}
+	}
+	if !qt.trackOrder {
+		return
+	}
+	if qt.order < MaxParamValues {
+		qt.order++
+	}
+	if qt.state.firstSeen[index] == 0 {
+		qt.state.firstSeen[index] = qt.order
+	}
+}
+
+// firstSeenIn returns earliest position among parameters in mask, 0 if none was seen
+func (qt *queryTracker) firstSeenIn(mask ParamMask) int {
+	first := 0
+	if !qt.trackOrder {
+		return first
+	}
+	for i := 0; i < MaxParamsCount; i++ {
+		if seen := int(qt.state.firstSeen[i]); seen != 0 && mask.GetBitUnsafe(i) && (first == 0 || seen < first) {
+			first = seen
+		}
+	}
+	return first
+}
+
+// trackQueryPresence records every known parameter of query in tracker without validating values
+func (rs *ruleSnapshot) trackQueryPresence(qt *queryTracker, queryString string, active ParamMask) {
+	qt.query = queryString
+	if qt.state != nil {
+		qt.trackOrder = rs.compiledRules.hasClauses
+		qt.trackValues = rs.compiledRules.hasAssertions
+	}
+	start := 0
+	for i := 0; i <= len(queryString); i++ {
+		if i == len(queryString) || queryString[i] == '&' {
+			if start < i {
+				qt.markPresent(rs.segmentIndex(queryString[start:i], active), start, i)
+			}
+			start = i + 1
+		}
+	}
+}
+
+// trackerSatisfied checks per-query constraints that can only be evaluated after the last segment
+func (rs *ruleSnapshot) trackerSatisfied(qt *queryTracker, masks ParamMasks, urlPath string) bool {
+	if qt.countOccurrences && !rs.occurrencesSatisfied(&qt.state.occurrences, qt.present, masks, urlPath) {
+		return false
+	}
+	return rs.clausesSatisfied(qt, urlPath)
+}
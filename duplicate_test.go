@@ -0,0 +1,120 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDuplicatePolicyRules(t *testing.T) {
+	rules := "/search?sort=[name,date]@first&page=[1,2,3]@last&id=[*]@reject&tags=[*]@allow"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"single occurrences", "/search?sort=name&page=1&id=5&tags=a", true},
+		{"first kept and valid", "/search?sort=name&sort=date", true},
+		{"first kept and invalid", "/search?sort=bogus&sort=name", false},
+		{"ignored after first invalid", "/search?sort=name&sort=bogus", false},
+		{"last kept and valid", "/search?page=1&page=2", true},
+		{"last kept and invalid", "/search?page=2&page=9", false},
+		{"ignored before last invalid", "/search?page=9&page=2", false},
+		{"reject repeated", "/search?id=1&id=2", false},
+		{"allow repeated", "/search?tags=a&tags=b", true},
+	}
+
+	pv := newTestValidator(t, rules)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := tt.url[strings.Index(tt.url, "?")+1:]
+			if result := pv.ValidateQuery("/search", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/search"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDuplicatePolicyOption(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&tags=[*]{1,3}&sort=[name]@allow", WithDuplicatePolicy(DuplicateReject))
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/search?q=a&tags=x", true},
+		{"/search?q=a&q=b&tags=x", false},
+		{"/search?tags=x&tags=y", true},
+		{"/search?tags=x&sort=name&sort=name", true},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestDuplicatePolicyFilter(t *testing.T) {
+	pv := newTestValidator(t, "/search?sort=[name,date]@first&page=[1,2,3]@last&id=[*]@reject&q=[*]")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?sort=date&q=x&sort=name", "/search?sort=date&q=x"},
+		{"/search?page=1&q=x&page=3", "/search?q=x&page=3"},
+		{"/search?page=1&q=x&page=7", "/search?q=x"},
+		{"/search?id=1&q=x&id=2", "/search?q=x"},
+		{"/search?id=1&q=x", "/search?id=1&q=x"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+
+		query := tt.url[strings.Index(tt.url, "?")+1:]
+		expectedQuery := ""
+		if i := strings.Index(tt.expected, "?"); i != -1 {
+			expectedQuery = tt.expected[i+1:]
+		}
+		if result := pv.FilterQuery("/search", query); result != expectedQuery {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", query, result, expectedQuery)
+		}
+		buffer := make([]byte, 0, len(query))
+		if result := pv.FilterQueryBytes([]byte("/search"), []byte(query), buffer); string(result) != expectedQuery {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", query, result, expectedQuery)
+		}
+	}
+}
+
+func TestDuplicatePolicyDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/search?id=[*]@reject&sort=[name]@first")
+
+	result := pv.ValidateURLDetailed("/search?id=1&id=2&id=3&sort=name&sort=name")
+	if len(result.Violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %+v", result.Violations)
+	}
+	violation := result.Violations[0]
+	if violation.Kind != ViolationDuplicate || violation.Param != "id" || violation.URLPattern != "/search" {
+		t.Errorf("Expected duplicate id violation, got %+v", violation)
+	}
+
+	// Ignored occurrence is reported when its value is invalid
+	result = pv.ValidateURLDetailed("/search?sort=name&sort=bogus")
+	if len(result.Violations) != 1 || result.Violations[0].Kind != ViolationEnumMismatch || result.Violations[0].Value != "bogus" {
+		t.Errorf("Expected enum mismatch for ignored sort, got %+v", result.Violations)
+	}
+}
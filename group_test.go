@@ -0,0 +1,137 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParamGroups(t *testing.T) {
+	rules := "/search?q=[*]&barcode=[*]&sku=[*]&last_days=[*]&from=[*]&to=[*]&page=[*]; " +
+		"oneof(q,barcode,sku); exclusive(last_days | from,to)"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"single oneof member", "/search?q=phone", true},
+		{"other oneof member", "/search?sku=A1&page=2", true},
+		{"no oneof member", "/search?page=2", false},
+		{"no params", "/search", false},
+		{"two oneof members", "/search?q=phone&barcode=123", false},
+		{"repeated oneof member", "/search?q=phone&q=tablet", true},
+		{"exclusive single alternative", "/search?q=phone&last_days=7", true},
+		{"exclusive multi member alternative", "/search?q=phone&from=1&to=2", true},
+		{"exclusive partial alternative", "/search?q=phone&from=1", true},
+		{"exclusive conflict", "/search?q=phone&last_days=7&to=2", false},
+	}
+
+	pv := newTestValidator(t, rules)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := ""
+			if i := strings.Index(tt.url, "?"); i != -1 {
+				query = tt.url[i+1:]
+			}
+			if result := pv.ValidateQuery("/search", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/search"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParamGroupAtLeastOne(t *testing.T) {
+	pv := newTestValidator(t, "contact=[*]; /users?email=[*]&phone=[*]&name=[*]; atleastone(email,phone)")
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/users?email=a", true},
+		{"/users?email=a&phone=1", true},
+		{"/users?name=bob", false},
+		{"/users", false},
+		{"/other?contact=x", true},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestParamGroupDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&barcode=[*]&sku=[*]&last_days=[*]&from=[*]&to=[*]; "+
+		"oneof(q,barcode,sku); exclusive(last_days | from,to)")
+
+	result := pv.ValidateURLDetailed("/search?last_days=7&from=1")
+	if len(result.Violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", result.Violations)
+	}
+
+	missing := result.Violations[0]
+	if missing.Kind != ViolationGroupMissing || missing.Value != "q,barcode,sku" ||
+		missing.Clause != "oneof(q | barcode | sku)" || missing.URLPattern != "/search" || missing.Source != SourceURL {
+		t.Errorf("Unexpected missing group violation: %+v", missing)
+	}
+
+	conflict := result.Violations[1]
+	if conflict.Kind != ViolationGroupConflict || conflict.Value != "last_days,from" ||
+		conflict.Clause != "exclusive(last_days | from,to)" {
+		t.Errorf("Unexpected group conflict violation: %+v", conflict)
+	}
+}
+
+func TestParamGroupFilter(t *testing.T) {
+	pv := newTestValidator(t, "/search?q=[*]&barcode=[*]&sku=[*]&last_days=[*]&from=[*]&to=[*]&page=[*]; "+
+		"oneof(q,barcode,sku); exclusive(last_days | from,to)")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?q=phone&page=2", "/search?q=phone&page=2"},
+		{"/search?barcode=1&q=phone&sku=A", "/search?barcode=1"},
+		{"/search?q=phone&to=2&last_days=7&from=1", "/search?q=phone&to=2&from=1"},
+		{"/search?q=phone&last_days=7&to=2", "/search?q=phone&last_days=7"},
+		{"/search?page=2", ""},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	query := []byte("sku=A&page=2&q=phone")
+	buffer := make([]byte, 0, len(query))
+	if result := pv.FilterQueryBytes([]byte("/search"), query, buffer); string(result) != "sku=A&page=2" {
+		t.Errorf("FilterQueryBytes = %q", result)
+	}
+	if result := pv.FilterQuery("/search", "page=2"); result != "" {
+		t.Errorf("FilterQuery without oneof member = %q, expected empty", result)
+	}
+}
+
+func TestParamGroupAllowAll(t *testing.T) {
+	pv := newTestValidator(t, "from=[*]&last_days=[*]; exclusive(from | last_days); /api/*?*")
+
+	if pv.ValidateURL("/api/report?last_days=7&from=1&x=1") {
+		t.Error("Expected exclusive group to apply under allow-all rule")
+	}
+	if result := pv.FilterURL("/api/report?last_days=7&x=1&from=1"); result != "/api/report?last_days=7&x=1" {
+		t.Errorf("FilterURL = %q", result)
+	}
+}
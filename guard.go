@@ -0,0 +1,127 @@
+// guard.go
+package paramvalidator
+
+import (
+	"fmt"
+)
+
+// RuleCorpus holds URLs replayed against candidate rules before they are applied
+type RuleCorpus struct {
+	// Accept lists known-good URLs candidate rules must accept
+	Accept []string
+	// Reject lists known-bad URLs candidate rules must reject
+	Reject []string
+	// Sample lists URLs whose verdicts may change within policy
+	Sample []string
+}
+
+// GuardPolicy limits how much candidate rules may change verdicts of corpus
+type GuardPolicy struct {
+	// MaxChangedPercent is share of corpus URLs in percent whose verdict or filtered output may change
+	MaxChangedPercent float64
+}
+
+// GuardReport describes replay of corpus against candidate rules
+type GuardReport struct {
+	Applied        bool
+	Checked        int
+	Changed        []DiffEvent
+	ChangedPercent float64
+	// BrokenAccept lists known-good URLs candidate rules reject
+	BrokenAccept []string
+	// BrokenReject lists known-bad URLs candidate rules accept
+	BrokenReject []string
+}
+
+// ApplyRulesGuarded replaces rules only if candidate rules keep corpus verdicts within policy
+// Corpus is replayed without blocking validation or rule updates, applied rules are recorded in history with info
+// Report lists every changed verdict even when rules are refused
+func (pv *ParamValidator) ApplyRulesGuarded(rulesStr string, info RuleInfo, corpus RuleCorpus, policy GuardPolicy) (GuardReport, error) {
+	if !pv.initialized.Load() {
+		return GuardReport{}, fmt.Errorf("validator not initialized")
+	}
+	if rulesStr == "" {
+		return GuardReport{}, fmt.Errorf("guarded rules must not be empty")
+	}
+	if err := pv.checkSize(rulesStr, MaxRulesSize, "rules string"); err != nil {
+		return GuardReport{}, err
+	}
+
+	for {
+		current := pv.current()
+		// Candidate gets own validator cache so refused rules leave cache of loaded rules intact
+		candidate, err := current.compile(pv.parser.isolated(), rulesStr)
+		if err != nil {
+			return GuardReport{}, fmt.Errorf("failed to parse candidate rules: %w", err)
+		}
+
+		report, err := replayCorpus(current, candidate, corpus, policy)
+		if err != nil {
+			return report, err
+		}
+
+		// Rules updated during replay invalidate report, corpus is replayed against them again
+		pv.mu.Lock()
+		if pv.current() == current {
+			pv.publishUnsafe(candidate, info)
+			pv.mu.Unlock()
+			report.Applied = true
+			return report, nil
+		}
+		pv.mu.Unlock()
+	}
+}
+
+// replayCorpus replays corpus against current and candidate snapshots and checks result against policy
+func replayCorpus(current, candidate *ruleSnapshot, corpus RuleCorpus, policy GuardPolicy) (GuardReport, error) {
+	var report GuardReport
+	current, candidate = current.withoutObservers(), candidate.withoutObservers()
+
+	for _, fullURL := range corpus.Accept {
+		if !candidate.validateURL(fullURL) {
+			report.BrokenAccept = append(report.BrokenAccept, fullURL)
+		}
+	}
+	for _, fullURL := range corpus.Reject {
+		if candidate.validateURL(fullURL) {
+			report.BrokenReject = append(report.BrokenReject, fullURL)
+		}
+	}
+	for _, urls := range [][]string{corpus.Accept, corpus.Reject, corpus.Sample} {
+		for _, fullURL := range urls {
+			if event, differs := compareEnforced(current, candidate, fullURL); differs {
+				report.Changed = append(report.Changed, event)
+			}
+			report.Checked++
+		}
+	}
+	if report.Checked > 0 {
+		report.ChangedPercent = float64(len(report.Changed)) * 100 / float64(report.Checked)
+	}
+
+	if len(report.BrokenAccept) > 0 || len(report.BrokenReject) > 0 {
+		return report, fmt.Errorf("candidate rules break %d known-good and %d known-bad URLs",
+			len(report.BrokenAccept), len(report.BrokenReject))
+	}
+	if report.ChangedPercent > policy.MaxChangedPercent {
+		return report, fmt.Errorf("candidate rules change %.2f%% of verdicts, policy allows %.2f%%",
+			report.ChangedPercent, policy.MaxChangedPercent)
+	}
+	return report, nil
+}
+
+// isolated returns parser sharing plugins of rp with empty validator cache
+func (rp *RuleParser) isolated() *RuleParser {
+	return NewRuleParser(rp.plugins...)
+}
+
+// compareEnforced compares enforced verdicts of two snapshots for URL
+func compareEnforced(current, candidate *ruleSnapshot, fullURL string) (DiffEvent, bool) {
+	return diffEvent(DiffEvent{
+		URL:               fullURL,
+		CurrentValid:      current.validateURL(fullURL),
+		CandidateValid:    candidate.validateURL(fullURL),
+		CurrentFiltered:   current.filterURL(fullURL),
+		CandidateFiltered: candidate.filterURL(fullURL),
+	})
+}
@@ -0,0 +1,124 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+const guardRules = "/list?page=[1,2,3]&sort=[asc,desc]"
+
+var guardCorpus = RuleCorpus{
+	Accept: []string{"/list?page=1", "/list?sort=asc", "/list?page=2&sort=desc"},
+	Reject: []string{"/list?page=9", "/list?debug=1"},
+	Sample: []string{"/list?page=3", "/list?sort=rank", "/list?page=1&sort=rank"},
+}
+
+func TestApplyRulesGuarded(t *testing.T) {
+	tests := []struct {
+		name         string
+		rules        string
+		policy       GuardPolicy
+		applied      bool
+		changed      int
+		brokenAccept int
+		brokenReject int
+	}{
+		{"unchanged", guardRules, GuardPolicy{}, true, 0, 0, 0},
+		{"within policy", "/list?page=[1,2,3]&sort=[asc,desc,rank]", GuardPolicy{MaxChangedPercent: 25}, true, 2, 0, 0},
+		{"over policy", "/list?page=[1,2,3]&sort=[asc,desc,rank]", GuardPolicy{MaxChangedPercent: 20}, false, 2, 0, 0},
+		{"missing ampersand", "/list?page=[1,2,3]sort=[asc,desc]", GuardPolicy{MaxChangedPercent: 100}, false, 2, 2, 0},
+		{"too permissive", "/list?*", GuardPolicy{MaxChangedPercent: 100}, false, 4, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, guardRules)
+
+			report, err := pv.ApplyRulesGuarded(tt.rules, RuleInfo{}, guardCorpus, tt.policy)
+			if (err == nil) != tt.applied || report.Applied != tt.applied {
+				t.Errorf("Applied = %v, error = %v, expected applied %v", report.Applied, err, tt.applied)
+			}
+			if report.Checked != 8 || len(report.Changed) != tt.changed {
+				t.Errorf("Checked %d URLs with %d changes, expected 8 and %d: %+v", report.Checked, len(report.Changed), tt.changed, report.Changed)
+			}
+			if len(report.BrokenAccept) != tt.brokenAccept || len(report.BrokenReject) != tt.brokenReject {
+				t.Errorf("BrokenAccept = %v, BrokenReject = %v", report.BrokenAccept, report.BrokenReject)
+			}
+
+			rules, _ := pv.RulesString()
+			if expected := map[bool]string{true: tt.rules, false: guardRules}[tt.applied]; rules != expected {
+				t.Errorf("RulesString = %q, expected %q", rules, expected)
+			}
+		})
+	}
+}
+
+func TestApplyRulesGuardedKeepsConfiguration(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, guardRules, WithCaseFolding(CaseFoldValues), WithReportOnly(func(report Report) {
+		reports = append(reports, report)
+	}))
+
+	corpus := RuleCorpus{Accept: []string{"/list?sort=ASC"}, Reject: []string{"/list?sort=up"}}
+	report, err := pv.ApplyRulesGuarded("/list?sort=[asc,desc]", RuleInfo{}, corpus, GuardPolicy{})
+	if err != nil || !report.Applied {
+		t.Errorf("Expected rules to be applied, got %+v, error %v", report, err)
+	}
+	if len(reports) != 0 {
+		t.Errorf("Expected corpus replay not to be reported, got %+v", reports)
+	}
+}
+
+func TestApplyRulesGuardedKeepsParserCache(t *testing.T) {
+	pv := newTestValidator(t, "/list?page=[range:1..10]", WithPlugins(plugins.NewRangePlugin()))
+
+	corpus := RuleCorpus{Accept: []string{"/list?page=5"}}
+	if report, err := pv.ApplyRulesGuarded("/list?page=[range:6..10]", RuleInfo{}, corpus, GuardPolicy{}); err == nil || report.Applied {
+		t.Fatalf("Expected candidate rules to be refused, got %+v", report)
+	}
+	if size := pv.parser.cache.Size(); size != 1 {
+		t.Errorf("Parser cache holds %d validators after refused rules, expected 1", size)
+	}
+	if _, found := pv.parser.cache.Get("range", "page", "range:1..10"); !found {
+		t.Error("Expected validator of loaded rules to stay cached")
+	}
+}
+
+func TestApplyRulesGuardedConcurrentUpdate(t *testing.T) {
+	var pv *ParamValidator
+	updated := false
+	pv = newTestValidator(t, "/list?token=[?]", WithCallback(func(string, string) bool {
+		// Rules may be updated while corpus is replayed, replay must not hold the lock
+		if !updated {
+			updated = true
+			if err := pv.ParseRules("/list?token=[?]&page=[1]"); err != nil {
+				t.Errorf("ParseRules failed: %v", err)
+			}
+		}
+		return true
+	}))
+
+	corpus := RuleCorpus{Accept: []string{"/list?token=a&page=1"}}
+	report, err := pv.ApplyRulesGuarded("/list?token=[?]&page=[1,2]", RuleInfo{}, corpus, GuardPolicy{MaxChangedPercent: 100})
+	if err != nil || !report.Applied {
+		t.Fatalf("Expected rules to be applied, got %+v, error %v", report, err)
+	}
+	// Report compares candidate with rules loaded during first replay
+	if len(report.Changed) != 0 {
+		t.Errorf("Changed = %+v, expected corpus to be replayed against updated rules", report.Changed)
+	}
+	if rules, _ := pv.RulesString(); rules != "/list?token=[?]&page=[1,2]" {
+		t.Errorf("RulesString = %q, expected candidate rules", rules)
+	}
+}
+
+func TestApplyRulesGuardedInvalidRules(t *testing.T) {
+	pv := newTestValidator(t, guardRules)
+
+	for _, rules := range []string{"", "/list?page=[1,2"} {
+		if report, err := pv.ApplyRulesGuarded(rules, RuleInfo{}, guardCorpus, GuardPolicy{MaxChangedPercent: 100}); err == nil || report.Applied {
+			t.Errorf("ApplyRulesGuarded(%q) = %+v, expected error", rules, report)
+		}
+	}
+}
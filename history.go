@@ -0,0 +1,83 @@
+// history.go
+package paramvalidator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RuleInfo describes who applied rule set and why
+type RuleInfo struct {
+	Author  string
+	Comment string
+}
+
+// RuleVersion records rule set applied to validator
+type RuleVersion struct {
+	Version   uint64
+	AppliedAt time.Time
+	// Hash is hex encoded SHA-256 of rules string
+	Hash  string
+	Rules string
+	Info  RuleInfo
+}
+
+// WithHistorySize sets number of applied rule sets kept for rollback, zero disables history
+func WithHistorySize(size int) Option {
+	return func(pv *ParamValidator) {
+		pv.historySize = max(size, 0)
+	}
+}
+
+// History returns applied rule sets from oldest to current
+func (pv *ParamValidator) History() []RuleVersion {
+	pv.mu.RLock()
+	defer pv.mu.RUnlock()
+	return append([]RuleVersion(nil), pv.history...)
+}
+
+// Rollback atomically restores rule set of version kept in history
+// Restored rules are recorded as new version
+func (pv *ParamValidator) Rollback(version uint64) error {
+	if !pv.initialized.Load() {
+		return fmt.Errorf("validator not initialized")
+	}
+
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	for _, entry := range pv.history {
+		if entry.Version != version {
+			continue
+		}
+		next, err := pv.buildSnapshotUnsafe(entry.Rules)
+		if err != nil {
+			return fmt.Errorf("failed to restore rules of version %d: %w", version, err)
+		}
+		pv.publishUnsafe(next, RuleInfo{Comment: fmt.Sprintf("rollback to version %d", version)})
+		return nil
+	}
+	return fmt.Errorf("version %d not found in history", version)
+}
+
+// recordRulesUnsafe appends applied rules to history dropping oldest versions beyond history size
+func (pv *ParamValidator) recordRulesUnsafe(rulesStr string, info RuleInfo) {
+	if pv.historySize == 0 {
+		return
+	}
+
+	pv.lastVersion++
+	hash := sha256.Sum256([]byte(rulesStr))
+	pv.history = append(pv.history, RuleVersion{
+		Version:   pv.lastVersion,
+		AppliedAt: time.Now(),
+		Hash:      hex.EncodeToString(hash[:]),
+		Rules:     rulesStr,
+		Info:      info,
+	})
+	if excess := len(pv.history) - pv.historySize; excess > 0 {
+		pv.history = append(pv.history[:0], pv.history[excess:]...)
+	}
+}
@@ -0,0 +1,123 @@
+package paramvalidator
+
+import (
+	"testing"
+)
+
+func TestHistoryRecordsAppliedRules(t *testing.T) {
+	pv := newTestValidator(t, "page=[1,2]")
+
+	if err := pv.ParseRulesWithInfo("page=[1,2,3]", RuleInfo{Author: "alice", Comment: "allow page 3"}); err != nil {
+		t.Fatalf("ParseRulesWithInfo failed: %v", err)
+	}
+	if err := pv.ParseRules("page=[1,2"); err == nil {
+		t.Fatal("Expected invalid rules to fail")
+	}
+	pv.ClearRules()
+
+	history := pv.History()
+	expected := []struct {
+		version uint64
+		rules   string
+		info    RuleInfo
+	}{
+		{1, "page=[1,2]", RuleInfo{}},
+		{2, "page=[1,2,3]", RuleInfo{Author: "alice", Comment: "allow page 3"}},
+		{3, "", RuleInfo{}},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("History = %+v, expected %d versions", history, len(expected))
+	}
+	for i, want := range expected {
+		got := history[i]
+		if got.Version != want.version || got.Rules != want.rules || got.Info != want.info {
+			t.Errorf("History[%d] = %+v, expected %+v", i, got, want)
+		}
+		if len(got.Hash) != 64 || got.AppliedAt.IsZero() {
+			t.Errorf("History[%d] has hash %q and time %v", i, got.Hash, got.AppliedAt)
+		}
+	}
+	if history[0].Hash == history[1].Hash {
+		t.Error("Expected different rules to have different hashes")
+	}
+}
+
+func TestHistoryRollback(t *testing.T) {
+	pv := newTestValidator(t, "page=[1,2]")
+	if err := pv.ParseRules("sort=[asc]"); err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+
+	if err := pv.Rollback(1); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if !pv.ValidateURL("/?page=1") || pv.ValidateURL("/?sort=asc") {
+		t.Error("Expected rules of version 1 to be restored")
+	}
+	if rules, _ := pv.RulesString(); rules != "page=[1,2]" {
+		t.Errorf("RulesString = %q after rollback", rules)
+	}
+
+	history := pv.History()
+	last := history[len(history)-1]
+	if last.Version != 3 || last.Hash != history[0].Hash || last.Info.Comment != "rollback to version 1" {
+		t.Errorf("Expected rollback to be recorded as new version, got %+v", last)
+	}
+
+	if err := pv.Rollback(42); err == nil {
+		t.Error("Expected rollback to unknown version to fail")
+	}
+}
+
+func TestHistorySize(t *testing.T) {
+	tests := []struct {
+		size     int
+		versions []uint64
+	}{
+		{0, nil},
+		{2, []uint64{3, 4}},
+		{DefaultHistorySize, []uint64{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		pv := newTestValidator(t, "a=[*]", WithHistorySize(tt.size))
+		for _, rules := range []string{"b=[*]", "c=[*]", "d=[*]"} {
+			if err := pv.ParseRules(rules); err != nil {
+				t.Fatalf("ParseRules(%q) failed: %v", rules, err)
+			}
+		}
+
+		history := pv.History()
+		if len(history) != len(tt.versions) {
+			t.Errorf("size %d: History = %+v, expected versions %v", tt.size, history, tt.versions)
+			continue
+		}
+		for i, version := range tt.versions {
+			if history[i].Version != version {
+				t.Errorf("size %d: History[%d].Version = %d, expected %d", tt.size, i, history[i].Version, version)
+			}
+		}
+		if len(history) > 0 {
+			if err := pv.Rollback(history[0].Version - 1); err == nil {
+				t.Errorf("size %d: expected version older than history to be unavailable", tt.size)
+			}
+		}
+	}
+}
+
+func TestHistoryGuardedApply(t *testing.T) {
+	pv := newTestValidator(t, "page=[1,2]")
+
+	corpus := RuleCorpus{Accept: []string{"/?page=1"}}
+	info := RuleInfo{Author: "alice", Comment: "narrow page"}
+	if _, err := pv.ApplyRulesGuarded("page=[1]", info, corpus, GuardPolicy{MaxChangedPercent: 100}); err != nil {
+		t.Fatalf("ApplyRulesGuarded failed: %v", err)
+	}
+	if _, err := pv.ApplyRulesGuarded("page=[2]", info, corpus, GuardPolicy{MaxChangedPercent: 100}); err == nil {
+		t.Fatal("Expected guarded apply to refuse rules")
+	}
+
+	if history := pv.History(); len(history) != 2 || history[1].Rules != "page=[1]" || history[1].Info != info {
+		t.Errorf("Expected only applied rules in history, got %+v", history)
+	}
+}
@@ -0,0 +1,100 @@
+// mapping.go
+package paramvalidator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValueMapping maps deprecated enum value to allowed value filtering writes instead
+type ValueMapping struct {
+	From string
+	To   string
+}
+
+// Rewrite reports accepted value filtering replaces by mapped value
+type Rewrite struct {
+	Param     string
+	Value     string
+	Rewritten string
+}
+
+// parseValueMappings splits trailing mappings such as "; newest->date_desc, 1->grid" off enum constraint
+// Constraint is kept whole unless every part after the last ';' is a mapping, so ';' inside constraints is kept
+func parseValueMappings(constraintStr string) ([]ValueMapping, string, error) {
+	sep := strings.LastIndexByte(constraintStr, ';')
+	if sep == -1 || !strings.Contains(constraintStr[sep+1:], "->") {
+		return nil, constraintStr, nil
+	}
+
+	parts := strings.Split(constraintStr[sep+1:], ",")
+	mappings := make([]ValueMapping, 0, len(parts))
+	for _, part := range parts {
+		from, to, found := strings.Cut(part, "->")
+		if !found {
+			return nil, constraintStr, nil
+		}
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if from == "" || to == "" {
+			return nil, "", fmt.Errorf("empty value in mapping '%s'", strings.TrimSpace(part))
+		}
+		mappings = append(mappings, ValueMapping{From: from, To: to})
+	}
+	return mappings, strings.TrimSpace(constraintStr[:sep]), nil
+}
+
+// checkValueMappings checks that mappings lead from values outside enum to values of enum
+func checkValueMappings(rule *ParamRule) error {
+	if rule.Pattern != PatternEnum {
+		return fmt.Errorf("value mappings of parameter '%s' require enum constraint", rule.Name)
+	}
+
+	for i, mapping := range rule.Mappings {
+		if slices.Contains(rule.Values, mapping.From) {
+			return fmt.Errorf("mapped value '%s' of parameter '%s' is already allowed", mapping.From, rule.Name)
+		}
+		if !slices.Contains(rule.Values, mapping.To) {
+			return fmt.Errorf("value '%s' is mapped to '%s' not allowed for parameter '%s'", mapping.From, mapping.To, rule.Name)
+		}
+		for _, previous := range rule.Mappings[:i] {
+			if previous.From == mapping.From {
+				return fmt.Errorf("duplicate mapping of value '%s' for parameter '%s'", mapping.From, rule.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// mapValue returns value that mappings map value to
+func mapValue[T ~string | ~[]byte](mappings []ValueMapping, value T, fold bool) (string, bool) {
+	for _, mapping := range mappings {
+		if fold && equalFold(value, mapping.From) || !fold && string(value) == mapping.From {
+			return mapping.To, true
+		}
+	}
+	return "", false
+}
+
+// matchesDiscriminator checks if discriminator value or value it is mapped to is one of values
+func matchesDiscriminator(values []string, value []byte, mappings []ValueMapping, fold bool) bool {
+	if containsValue(values, value, fold) {
+		return true
+	}
+	mapped, ok := mapValue(mappings, value, fold)
+	return ok && containsValue(values, mapped, fold)
+}
+
+// collectRewrite records mapping of accepted value in detailed result
+func (rs *ruleSnapshot) collectRewrite(result *ValidationResult, rule *ParamRule, key, value string) {
+	if rule == nil || len(rule.Mappings) == 0 {
+		return
+	}
+	_, decoded, ok := rs.decodeKeyValue(key, value)
+	if !ok {
+		return
+	}
+	if mapped, ok := mapValue(rule.Mappings, rule.transformValue(decoded), rs.foldsValues(rule)); ok {
+		result.Rewrites = append(result.Rewrites, Rewrite{Param: key, Value: decoded, Rewritten: mapped})
+	}
+}
@@ -0,0 +1,205 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+)
+
+const mappingRules = "/list?sort=[date_desc,price_asc; newest->date_desc, cheap->price_asc]&view=[grid,list; 1->grid, 2->list]&q=[*]"
+
+func TestMappingValidate(t *testing.T) {
+	pv := newTestValidator(t, mappingRules)
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"sort=date_desc", true},
+		{"sort=newest", true},
+		{"sort=cheap&view=1", true},
+		{"view=3", false},
+		{"sort=oldest", false},
+		{"sort=Newest", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL("/list?" + tt.query); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQuery("/list", tt.query); result != tt.expected {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQueryBytes([]byte("/list"), []byte(tt.query)); result != tt.expected {
+			t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateURLDetailed("/list?" + tt.query); result.Valid != tt.expected {
+			t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.query, result.Valid, tt.expected)
+		}
+	}
+}
+
+func TestMappingFilterRewritesValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		query    string
+		expected string
+	}{
+		{"mapped", nil, "sort=newest&view=1&q=a", "sort=date_desc&view=grid&q=a"},
+		{"allowed kept", nil, "sort=price_asc&view=list", "sort=price_asc&view=list"},
+		{"unknown dropped", nil, "sort=oldest&view=2", "view=list"},
+		{"mapped with folding", []Option{WithCaseFolding(CaseFoldValues)}, "sort=NEWEST", "sort=date_desc"},
+		{"mapped with decoding", []Option{WithDecoding(DecodeQuery)}, "sort=%6Eewest", "sort=date_desc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, mappingRules, tt.options...)
+
+			if result := pv.FilterQuery("/list", tt.query); result != tt.expected {
+				t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.expected)
+			}
+			buffer := make([]byte, 0, 2*len(tt.query))
+			if result := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), buffer); string(result) != tt.expected {
+				t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMappingDetailed(t *testing.T) {
+	pv := newTestValidator(t, mappingRules)
+
+	result := pv.ValidateURLDetailed("/list?sort=newest&view=grid&q=a")
+	if !result.Valid || len(result.Violations) != 0 {
+		t.Fatalf("Expected valid result, got %+v", result)
+	}
+	if len(result.Rewrites) != 1 {
+		t.Fatalf("Expected one rewrite, got %+v", result.Rewrites)
+	}
+	expected := Rewrite{Param: "sort", Value: "newest", Rewritten: "date_desc"}
+	if result.Rewrites[0] != expected {
+		t.Errorf("Rewrite = %+v, expected %+v", result.Rewrites[0], expected)
+	}
+}
+
+func TestMappingDuplicates(t *testing.T) {
+	pv := newTestValidator(t, "/list?sort=[date_desc,price_asc; newest->date_desc, cheap->price_asc]@first&"+
+		"view=[grid,list; 1->grid, 2->list]@last&mode=[a,b; x->a]@reject&q=[*]")
+
+	tests := []struct {
+		query    string
+		valid    bool
+		filtered string
+	}{
+		{"sort=newest&sort=cheap", true, "sort=date_desc"},
+		{"sort=newest&sort=oldest", false, "sort=date_desc"},
+		{"view=1&view=2", true, "view=list"},
+		{"view=list&view=1", true, "view=grid"},
+		{"view=9&view=1", false, "view=grid"},
+		{"mode=x", true, "mode=a"},
+		// Mapped and mapping value are still two occurrences
+		{"mode=x&mode=a", false, ""},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateQuery("/list", tt.query); result != tt.valid {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.valid)
+		}
+		if result := pv.FilterQuery("/list", tt.query); result != tt.filtered {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.filtered)
+		}
+		buffer := make([]byte, 0, 2*len(tt.query))
+		if result := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), buffer); string(result) != tt.filtered {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.filtered)
+		}
+	}
+}
+
+func TestMappingConditions(t *testing.T) {
+	pv := newTestValidator(t, "/search?type=[lower|image,video; img->image]&q=[*]; variant(type=image) width=[*]; "+
+		"/catalog?category=[shoes,books; footwear->shoes]&sub=[*]; when(category=shoes) sub=[sneakers,boots; trainers->sneakers]")
+
+	tests := []struct {
+		url      string
+		valid    bool
+		filtered string
+	}{
+		{"/search?type=IMG&width=10", true, "/search?type=image&width=10"},
+		{"/search?type=img&type=image&width=10", true, "/search?type=image&type=image&width=10"},
+		{"/search?type=img&type=video&width=10", false, "/search"},
+		{"/catalog?category=footwear&sub=trainers", true, "/catalog?category=shoes&sub=sneakers"},
+		{"/catalog?category=footwear&sub=hats", false, "/catalog?category=shoes"},
+		{"/catalog?category=books&sub=trainers", true, "/catalog?category=books&sub=trainers"},
+		{"/catalog?category=books&category=footwear&sub=trainers", true, "/catalog?category=books&category=shoes&sub=sneakers"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.valid {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.valid)
+		}
+		if result := pv.FilterURL(tt.url); result != tt.filtered {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.filtered)
+		}
+	}
+
+	// Mapping of condition is reported like mapping of parameter rule
+	result := pv.ValidateURLDetailed("/catalog?category=footwear&sub=trainers")
+	expected := []Rewrite{
+		{Param: "category", Value: "footwear", Rewritten: "shoes"},
+		{Param: "sub", Value: "trainers", Rewritten: "sneakers"},
+	}
+	if !result.Valid || len(result.Rewrites) != len(expected) {
+		t.Fatalf("Result = %+v, expected rewrites %+v", result, expected)
+	}
+	for i := range expected {
+		if result.Rewrites[i] != expected[i] {
+			t.Errorf("Rewrite %d = %+v, expected %+v", i, result.Rewrites[i], expected[i])
+		}
+	}
+}
+
+func TestMappingFilterQueryBytesBuffer(t *testing.T) {
+	long := strings.Repeat("x", 150)
+	pv := newTestValidator(t, mappingRules+"&size=["+long+"; l->"+long+"]")
+
+	tests := []struct {
+		query    string
+		capacity int
+		expected string
+	}{
+		{"view=1", 6, ""},
+		{"view=1", 9, "view=grid"},
+		{"view=1&q=22222", 14, ""},
+		{"view=1&q=22222", 17, "view=grid&q=22222"},
+		{"q=1&view=1&sort=newest", 22, ""},
+		{"q=1&view=1&sort=newest", 32, "q=1&view=grid&sort=date_desc"},
+		// Rewritten segment longer than any fixed scratch array is still built in buffer
+		{"size=l", 155, "size=" + long},
+	}
+
+	for _, tt := range tests {
+		buffer := make([]byte, 0, tt.capacity)
+		result := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), buffer)
+		if string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q, cap %d) = %q, expected %q", tt.query, tt.capacity, result, tt.expected)
+			continue
+		}
+		if tt.expected == "" {
+			continue
+		}
+		if !usesBuffer(result, buffer) {
+			t.Errorf("FilterQueryBytes(%q) result does not share caller's buffer", tt.query)
+		}
+		if filtered := pv.FilterQuery("/list", tt.query); filtered != tt.expected {
+			t.Errorf("FilterQuery(%q) = %q, expected same result as bytes path", tt.query, filtered)
+		}
+		urlPath, query := []byte("/list"), []byte(tt.query)
+		allocs := testing.AllocsPerRun(100, func() {
+			pv.FilterQueryBytes(urlPath, query, buffer)
+		})
+		if allocs != 0 {
+			t.Errorf("FilterQueryBytes(%q) allocated %v times, expected 0", tt.query, allocs)
+		}
+	}
+}
@@ -171,18 +171,33 @@ func (pi *ParamIndex) GetParamName(index int) string {
 
 // Clear clears the index
 func (pi *ParamIndex) Clear() {
-	pi.paramToIndex = sync.Map{}
+	pi.paramToIndex.Clear()
+	pi.aliasToIndex.Clear()
+	pi.hasAliases.Store(false)
 	pi.nextIndex.Store(0)
 }
 
 // GetIndex returns parameter index or -1 if not found
+// Aliases resolve to index of their primary name
 func (pi *ParamIndex) GetIndex(paramName string) int {
 	if idx, ok := pi.paramToIndex.Load(paramName); ok {
 		return idx.(int)
 	}
+	if !pi.hasAliases.Load() {
+		return -1
+	}
+	if idx, ok := pi.aliasToIndex.Load(paramName); ok {
+		return idx.(int)
+	}
 	return -1
 }
 
+// AddAlias makes alias resolve to index of primary parameter name
+func (pi *ParamIndex) AddAlias(alias string, index int) {
+	pi.aliasToIndex.Store(alias, index)
+	pi.hasAliases.Store(true)
+}
+
 // GetBitUnsafe fast version without bounds checking
 func (pm ParamMask) GetBitUnsafe(index int) bool {
 	part := index / 32
@@ -246,8 +261,16 @@ func (pm ParamMasks) GetRuleSource(paramIndex int) RuleSource {
 
 // GetIndexByBytes finds parameter by []byte without creating string
 func (pi *ParamIndex) GetIndexByBytes(keyBytes []byte) int {
+	if idx := indexByBytes(&pi.paramToIndex, keyBytes); idx != -1 || !pi.hasAliases.Load() {
+		return idx
+	}
+	return indexByBytes(&pi.aliasToIndex, keyBytes)
+}
+
+// indexByBytes scans name to index map for key in []byte form
+func indexByBytes(names *sync.Map, keyBytes []byte) int {
 	var result int = -1
-	pi.paramToIndex.Range(func(key, value interface{}) bool {
+	names.Range(func(key, value interface{}) bool {
 		paramName := key.(string)
 		if len(paramName) == len(keyBytes) {
 			// Byte-by-byte comparison
@@ -0,0 +1,66 @@
+// occurrence.go
+package paramvalidator
+
+// occurrenceCounter counts parameter occurrences within single query by parameter index
+type occurrenceCounter struct {
+	counts [MaxParamsCount]uint16
+}
+
+// add registers occurrence of checked segment
+// Returns false if occurrence exceeds maximum allowed by the rule
+func (oc *occurrenceCounter) add(check segmentCheck) bool {
+	count := oc.increment(check.index)
+	return check.rule == nil || check.rule.MaxOccurs == 0 || count <= check.rule.MaxOccurs
+}
+
+// increment registers occurrence of parameter index and returns updated count
+func (oc *occurrenceCounter) increment(index int) int {
+	if index < 0 || index >= MaxParamsCount {
+		return 0
+	}
+	if oc.counts[index] < MaxParamValues {
+		oc.counts[index]++
+	}
+	return int(oc.counts[index])
+}
+
+// count returns number of registered occurrences for parameter index
+func (oc *occurrenceCounter) count(index int) int {
+	if index < 0 || index >= MaxParamsCount {
+		return 0
+	}
+	return int(oc.counts[index])
+}
+
+// occurrencesSatisfied checks that every present parameter reaches its minimum occurrences
+func (rs *ruleSnapshot) occurrencesSatisfied(oc *occurrenceCounter, present ParamMask, masks ParamMasks, urlPath string) bool {
+	for i := 0; i < MaxParamsCount; i++ {
+		if !present.GetBitUnsafe(i) {
+			continue
+		}
+		if rule := rs.findParamRuleByIndex(i, masks, urlPath); rule != nil && oc.count(i) < rule.MinOccurs {
+			return false
+		}
+	}
+	return true
+}
+
+// collectOccurrenceViolations records violation for every present parameter below its minimum occurrences
+func (rs *ruleSnapshot) collectOccurrenceViolations(result *ValidationResult, oc *occurrenceCounter, present ParamMask, masks ParamMasks, urlPath string) {
+	for _, idx := range present.GetIndices() {
+		rule, urlRule := rs.resolveParamRuleByIndex(idx, masks, urlPath)
+		if rule == nil || oc.count(idx) == 0 || oc.count(idx) >= rule.MinOccurs {
+			continue
+		}
+
+		violation := Violation{
+			Param:  rule.Name,
+			Kind:   ViolationOccurrences,
+			Source: masks.GetRuleSource(idx),
+		}
+		if urlRule != nil {
+			violation.URLPattern = urlRule.URLPattern
+		}
+		result.addViolation(violation)
+	}
+}
@@ -0,0 +1,125 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestOccurrenceConstraints(t *testing.T) {
+	rules := "/search?tags=[*]{1,5}&page=[range:1..100]{0,1}&ids=[*]{2}&sort=[name,date]{,2}"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"within limits", "/search?tags=a&tags=b&page=2&ids=1&ids=2", true},
+		{"max reached", "/search?tags=a&tags=b&tags=c&tags=d&tags=e&ids=1&ids=2", true},
+		{"max exceeded", "/search?tags=a&tags=b&tags=c&tags=d&tags=e&tags=f&ids=1&ids=2", false},
+		{"optional single param repeated", "/search?tags=a&page=1&page=2&ids=1&ids=2", false},
+		{"min not reached", "/search?tags=a&ids=1", false},
+		{"exact count exceeded", "/search?tags=a&ids=1&ids=2&ids=3", false},
+		{"required by minimum missing", "/search?ids=1&ids=2", false},
+		{"open minimum", "/search?tags=a&ids=1&ids=2&sort=name&sort=date", true},
+		{"open minimum exceeded", "/search?tags=a&ids=1&ids=2&sort=name&sort=date&sort=name", false},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewRangePlugin()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := tt.url[strings.Index(tt.url, "?")+1:]
+			if result := pv.ValidateQuery("/search", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/search"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOccurrenceFilter(t *testing.T) {
+	pv := newTestValidator(t, "/search?tags=[*]{1,2}&page=[*]{0,1}&ids=[*]{2,}")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?tags=a&tags=b&tags=c&ids=1&ids=2", "/search?tags=a&tags=b&ids=1&ids=2"},
+		{"/search?tags=a&page=1&page=2&ids=1&ids=2&ids=3", "/search?tags=a&page=1&ids=1&ids=2&ids=3"},
+		{"/search?tags=a&ids=1", ""},
+		{"/search?ids=1&ids=2", ""},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	query := []byte("tags=a&tags=b&tags=c&ids=1&ids=2")
+	buffer := make([]byte, 0, len(query))
+	if result := pv.FilterQueryBytes([]byte("/search"), query, buffer); string(result) != "tags=a&tags=b&ids=1&ids=2" {
+		t.Errorf("FilterQueryBytes = %q", result)
+	}
+}
+
+func TestOccurrenceDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/search?tags=[*]{1,2}&ids=[*]{2,3}")
+
+	result := pv.ValidateURLDetailed("/search?tags=a&tags=b&tags=c&ids=1")
+	if len(result.Violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", result.Violations)
+	}
+	for _, violation := range result.Violations {
+		if violation.Kind != ViolationOccurrences {
+			t.Errorf("Expected occurrence violation, got %+v", violation)
+		}
+	}
+	if result.Violations[0].Param != "tags" || result.Violations[0].Value != "c" {
+		t.Errorf("Expected extra tags=c reported, got %+v", result.Violations[0])
+	}
+	if result.Violations[1].Param != "ids" || result.Violations[1].URLPattern != "/search" {
+		t.Errorf("Expected ids below minimum reported, got %+v", result.Violations[1])
+	}
+}
+
+func BenchmarkQueryTracker(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		rules string
+	}{
+		// Feature-free rules must not pay for per-parameter tracker state
+		{"FeatureFree", "/api/*?page=[5]&limit=[10]&sort=[name,date]"},
+		{"Occurrences", "/api/*?page=[5]&limit=[10]&sort=[name,date]{1,3}"},
+	}
+
+	urlPath := []byte("/api/data")
+	queryBytes := []byte("page=5&limit=10&sort=name&invalid=value")
+	buffer := make([]byte, 0, 256)
+
+	for _, bm := range benchmarks {
+		pv := newTestValidator(b, bm.rules)
+
+		b.Run(bm.name+"/ValidateQuery", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pv.ValidateQuery("/api/data", "page=5&limit=10&sort=name")
+			}
+		})
+		b.Run(bm.name+"/FilterQueryBytes", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pv.FilterQueryBytes(urlPath, queryBytes, buffer[:0])
+			}
+		})
+	}
+}
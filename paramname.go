@@ -0,0 +1,124 @@
+// paramname.go
+package paramvalidator
+
+import (
+	"sort"
+	"strings"
+)
+
+// paramNamePattern is compiled parameter name containing '*' wildcard
+type paramNamePattern struct {
+	pattern string
+	index   int
+	fold    bool
+}
+
+// isParamNamePattern checks if parameter name matches a family of keys
+func isParamNamePattern(name string) bool {
+	return name != PatternAll && strings.Contains(name, PatternAll)
+}
+
+// matchParamName matches key against name pattern
+// '*' matches any run of characters except brackets, so filter[*] matches filter[status] but not filter[a][b]
+// With fold literal ASCII letters match regardless of case
+func matchParamName[T ~string | ~[]byte](pattern string, key T, fold bool) bool {
+	p, k := 0, 0
+	starP, starK := -1, 0
+
+	for k < len(key) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starK = p, k
+			p++
+		case p < len(pattern) && (pattern[p] == key[k] || fold && equalFoldASCII(pattern[p], key[k])):
+			p++
+			k++
+		case starP != -1 && key[starK] != '[' && key[starK] != ']':
+			starK++
+			p, k = starP+1, starK
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// sortParamNamePatterns orders patterns from most to least specific
+// Patterns with more literal characters win, then patterns with fewer wildcards
+func sortParamNamePatterns(patterns []paramNamePattern) {
+	sort.Slice(patterns, func(i, j int) bool {
+		wildcardsI := strings.Count(patterns[i].pattern, PatternAll)
+		wildcardsJ := strings.Count(patterns[j].pattern, PatternAll)
+		literalI := len(patterns[i].pattern) - wildcardsI
+		literalJ := len(patterns[j].pattern) - wildcardsJ
+
+		if literalI != literalJ {
+			return literalI > literalJ
+		}
+		if wildcardsI != wildcardsJ {
+			return wildcardsI < wildcardsJ
+		}
+		return patterns[i].pattern < patterns[j].pattern
+	})
+}
+
+// addParamNamePattern registers wildcard parameter name once per index
+func (cr *CompiledRules) addParamNamePattern(name string, index int) {
+	if !isParamNamePattern(name) {
+		return
+	}
+	for _, existing := range cr.namePatterns {
+		if existing.index == index {
+			return
+		}
+	}
+	cr.namePatterns = append(cr.namePatterns, paramNamePattern{pattern: name, index: index})
+}
+
+// registerParamName indexes every lookup of rule name: glob pattern, aliases and case-folded names
+func (rs *ruleSnapshot) registerParamName(rule *ParamRule, index int) {
+	rs.compiledRules.addParamNamePattern(rule.Name, index)
+	rs.addParamAliases(rule, index)
+	rs.addFoldedParamName(rule, index)
+}
+
+// lookupParamIndex resolves query key to parameter index
+// Exact names active in mask take precedence, case-folded names and name patterns are scanned only on miss
+func (rs *ruleSnapshot) lookupParamIndex(key string, active ParamMask) int {
+	idx := rs.compiledRules.paramIndex.GetIndex(key)
+	if idx != -1 && active.GetBit(idx) {
+		return idx
+	}
+	if folded := lookupFoldedIndex(rs.compiledRules, key, active); folded != -1 {
+		return folded
+	}
+
+	for _, namePattern := range rs.compiledRules.namePatterns {
+		if active.GetBit(namePattern.index) && matchParamName(namePattern.pattern, key, namePattern.fold) {
+			return namePattern.index
+		}
+	}
+	return idx
+}
+
+// lookupParamIndexBytes resolves query key in []byte form to parameter index without allocations
+func (rs *ruleSnapshot) lookupParamIndexBytes(keyBytes []byte, active ParamMask) int {
+	idx := rs.compiledRules.paramIndex.GetIndexByBytes(keyBytes)
+	if idx != -1 && active.GetBit(idx) {
+		return idx
+	}
+	if folded := lookupFoldedIndex(rs.compiledRules, keyBytes, active); folded != -1 {
+		return folded
+	}
+
+	for _, namePattern := range rs.compiledRules.namePatterns {
+		if active.GetBit(namePattern.index) && matchParamName(namePattern.pattern, keyBytes, namePattern.fold) {
+			return namePattern.index
+		}
+	}
+	return idx
+}
@@ -0,0 +1,179 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestBracketedParamNames(t *testing.T) {
+	rules := `/search?ids[]=[range:1..1000]&"filter[status]"=[open,closed]&filter[*]=[len:..8]&sort=[name]`
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"array key", "/search?ids[]=1&ids[]=500", true},
+		{"array key out of range", "/search?ids[]=5000", false},
+		{"array key without brackets is unknown", "/search?ids=1", false},
+		{"quoted nested key", "/search?filter[status]=open", true},
+		{"quoted nested key invalid value", "/search?filter[status]=pending", false},
+		{"exact name wins over wildcard", "/search?filter[status]=short", false},
+		{"wildcard nested key", "/search?filter[color]=red&filter[size]=xl", true},
+		{"wildcard nested key too long", "/search?filter[color]=ultraviolet", false},
+		{"wildcard does not span groups", "/search?filter[a][b]=x", false},
+		{"plain param", "/search?sort=name&ids[]=7", true},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewRangePlugin(), plugins.NewLengthPlugin()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := tt.url[strings.Index(tt.url, "?")+1:]
+			if result := pv.ValidateQuery("/search", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/search"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBracketedParamNamesFilter(t *testing.T) {
+	pv := newTestValidator(t, `/search?ids[]=[*]&filter[*]=[open,closed];ids=[]`)
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?ids[]=1&ids[]=2&x=1", "/search?ids[]=1&ids[]=2"},
+		{"/search?filter[state]=open&filter[kind]=bad&filter[a][b]=open", "/search?filter[state]=open"},
+		{"/other?ids&ids[]=1", "/other?ids"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+
+		query := tt.url[strings.Index(tt.url, "?")+1:]
+		expectedQuery := tt.expected[strings.Index(tt.expected, "?")+1:]
+		buffer := make([]byte, 0, len(query))
+		path := []byte(tt.url[:strings.Index(tt.url, "?")])
+		if result := pv.FilterQueryBytes(path, []byte(query), buffer); string(result) != expectedQuery {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", query, result, expectedQuery)
+		}
+	}
+}
+
+func TestBracketedParamNamesDecoding(t *testing.T) {
+	pv := newTestValidator(t, "ids[]=[1,2,3]", WithDecoding(DecodeQuery))
+
+	if !pv.ValidateURL("/any?ids%5B%5D=1&ids%5B%5D=3") {
+		t.Error("Expected percent-encoded brackets to match decoded rule name")
+	}
+	if !pv.ValidateQueryBytesWithScratch([]byte("/any"), []byte("ids%5B%5D=2"), make([]byte, 0, 32)) {
+		t.Error("Expected ValidateQueryBytesWithScratch to match decoded rule name")
+	}
+}
+
+func TestMatchParamName(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		key      string
+		expected bool
+	}{
+		{"filter[*]", "filter[status]", true},
+		{"filter[*]", "filter[]", true},
+		{"filter[*]", "filter[a][b]", false},
+		{"filter[*]", "filters[a]", false},
+		{"filter[*][id]", "filter[user][id]", true},
+		{"filter[*][id]", "filter[user][name]", false},
+	}
+
+	for _, tt := range tests {
+		if result := matchParamName(tt.pattern, tt.key, false); result != tt.expected {
+			t.Errorf("matchParamName(%q, %q) = %v, expected %v", tt.pattern, tt.key, result, tt.expected)
+		}
+		if result := matchParamName(tt.pattern, []byte(tt.key), false); result != tt.expected {
+			t.Errorf("matchParamName(%q, []byte(%q)) = %v, expected %v", tt.pattern, tt.key, result, tt.expected)
+		}
+	}
+}
+
+func TestGlobParamNames(t *testing.T) {
+	rules := "utm_*=[len:..8]&utm_source_*=[a,b]&x-*=[*];/landing?utm_campaign=[spring,summer]&ref=[*]"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"glob matches", "/landing?utm_source=google&utm_medium=cpc", true},
+		{"glob value rejected", "/landing?utm_medium=newsletter", false},
+		{"exact name wins over glob", "/landing?utm_campaign=summer", true},
+		{"exact name rejects value accepted by glob", "/landing?utm_campaign=winter", false},
+		{"more specific glob wins", "/landing?utm_source_id=a", true},
+		{"more specific glob rejects", "/landing?utm_source_id=c", false},
+		{"glob with dash", "/other?x-trace=anything&x-request-id=42", true},
+		{"glob requires prefix", "/other?utm=google", false},
+		{"glob does not match brackets", "/other?utm_a[b]=c", false},
+		{"glob requires literal suffix match", "/other?xtrace=1", false},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewLengthPlugin()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			path := tt.url[:strings.Index(tt.url, "?")]
+			query := tt.url[strings.Index(tt.url, "?")+1:]
+			if result := pv.ValidateQuery(path, query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte(path), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+		})
+	}
+
+	if !pv.ValidateParam("/other", "utm_term", "shoes") {
+		t.Error("Expected ValidateParam to resolve glob name")
+	}
+	if result := pv.FilterURL("/landing?utm_source=google&utm_campaign=winter&utm_term=toolongvalue&ref=x"); result != "/landing?utm_source=google&ref=x" {
+		t.Errorf("FilterURL = %q", result)
+	}
+}
+
+func TestSortParamNamePatterns(t *testing.T) {
+	patterns := []paramNamePattern{
+		{pattern: "utm_*"},
+		{pattern: "*_id"},
+		{pattern: "utm_source_*"},
+		{pattern: "utm_*_*"},
+	}
+	sortParamNamePatterns(patterns)
+
+	expected := []string{"utm_source_*", "utm_*_*", "utm_*", "*_id"}
+	for i, name := range expected {
+		if patterns[i].pattern != name {
+			t.Errorf("patterns[%d] = %q, expected %q", i, patterns[i].pattern, name)
+		}
+	}
+}
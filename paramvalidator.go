@@ -2,9 +2,11 @@
 package paramvalidator
 
 import (
+	"bytes"
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
@@ -30,11 +32,8 @@ func WithPlugins(plugins ...PluginConstraintParser) Option {
 // NewParamValidator creates a new parameter validator with the given rules
 func NewParamValidator(rulesStr string, options ...Option) (*ParamValidator, error) {
 	pv := &ParamValidator{
-		globalParams: make(map[string]*ParamRule),
-		urlRules:     make(map[string]*URLRule),
-		urlMatcher:   NewURLMatcher(),
-		paramIndex:   NewParamIndex(),
-		parser:       NewRuleParser(),
+		validatorConfig: validatorConfig{parser: NewRuleParser()},
+		historySize:     DefaultHistorySize,
 	}
 	pv.initialized.Store(true)
 
@@ -42,6 +41,7 @@ func NewParamValidator(rulesStr string, options ...Option) (*ParamValidator, err
 	for _, option := range options {
 		option(pv)
 	}
+	pv.active.Store(newRuleSnapshot(&pv.validatorConfig, pv.callbackFunc))
 
 	if rulesStr != "" {
 		if err := pv.checkSize(rulesStr, MaxRulesSize, "rules string"); err != nil {
@@ -60,7 +60,7 @@ func NewParamValidator(rulesStr string, options ...Option) (*ParamValidator, err
 func (pv *ParamValidator) SetCallback(callback CallbackFunc) {
 	pv.mu.Lock()
 	defer pv.mu.Unlock()
-	pv.callbackFunc = callback
+	pv.active.Store(pv.current().withCallback(callback))
 }
 
 // checkSize validates input size against maximum allowed size
@@ -76,20 +76,23 @@ func (pv *ParamValidator) checkSize(input string, maxSize int, inputType string)
 	return nil
 }
 
-// withSafeAccess executes function with lock and initialization check
-func (pv *ParamValidator) withSafeAccess(fn func() bool) bool {
+// ValidateURL validates complete URL against loaded rules
+// In report-only mode rejected URL is reported and accepted
+func (pv *ParamValidator) ValidateURL(fullURL string) bool {
 	if !pv.initialized.Load() {
 		return false
 	}
-
-	pv.mu.RLock()
-	defer pv.mu.RUnlock()
-	return fn()
+	rs := pv.current()
+	if valid := rs.validateURL(fullURL); valid || rs.reportObserver == nil {
+		return valid
+	}
+	rs.reportURL(ReportValidate, fullURL, "")
+	return true
 }
 
-// ValidateURL validates complete URL against loaded rules
-func (pv *ParamValidator) ValidateURL(fullURL string) bool {
-	if !pv.initialized.Load() || fullURL == "" {
+// validateURL validates complete URL enforcing verdict
+func (rs *ruleSnapshot) validateURL(fullURL string) bool {
+	if fullURL == "" {
 		return false
 	}
 
@@ -106,42 +109,43 @@ func (pv *ParamValidator) ValidateURL(fullURL string) bool {
 		return false
 	}
 
-	pv.mu.RLock()
-	defer pv.mu.RUnlock()
-
-	return pv.validateURLUnsafe(u)
+	return rs.validateURLUnsafe(u)
 }
 
 // validateURLUnsafe validates URL without locking using masks
-func (pv *ParamValidator) validateURLUnsafe(u *url.URL) bool {
+func (rs *ruleSnapshot) validateURLUnsafe(u *url.URL) bool {
 	if u.RawQuery == "" {
-		return true
+		return rs.emptyQueryAllowed(u.Path)
 	}
 
-	if pv.compiledRules == nil || pv.compiledRules.paramIndex == nil {
+	if rs.compiledRules == nil || rs.compiledRules.paramIndex == nil {
 		return false
 	}
 
-	masks := pv.getParamMasksForURL(u.Path)
-
-	if idx := pv.compiledRules.paramIndex.GetIndex(PatternAll); idx != -1 && masks.CombinedMask().GetBit(idx) {
-		return true
-	}
+	masks := rs.getParamMasksForURL(u.Path)
+	rs.selectVariant(&masks, u.Path, u.RawQuery)
 
 	if masks.CombinedMask().IsEmpty() {
 		return false
 	}
 
-	return pv.validateQueryParams(u.RawQuery, masks, u.Path, false)
+	return rs.validateQueryParams(u.RawQuery, masks, u.Path, false)
 }
 
 // validateQueryParams universal query parameters validation
-func (pv *ParamValidator) validateQueryParams(queryString string, masks ParamMasks, urlPath string, useBytes bool) bool {
+func (rs *ruleSnapshot) validateQueryParams(queryString string, masks ParamMasks, urlPath string, useBytes bool) bool {
+	required := rs.requiredMaskWithVariant(masks, urlPath)
 	if queryString == "" {
-		return true
+		return rs.emptyQueryAllowed(urlPath)
 	}
 
-	allowAll := pv.isAllowAllParamsMasks(masks)
+	allowAll := rs.isAllowAllParamsMasks(masks)
+	trackPresence := !required.IsEmpty() || rs.compiledRules.hasClauses
+	var tracker queryTracker
+	if rs.compiledRules.needsTrackerState {
+		tracker.state = &trackerState{}
+	}
+	rs.initQueryTracker(&tracker, queryString, masks)
 	start := 0
 	paramCount := 0
 
@@ -149,26 +153,32 @@ func (pv *ParamValidator) validateQueryParams(queryString string, masks ParamMas
 		if i == len(queryString) || queryString[i] == '&' {
 			if start < i && paramCount < MaxParamValues {
 				if !allowAll {
-					var allowed bool
+					var check segmentCheck
 					if useBytes {
-						allowed = pv.isParamAllowedBytesSegment([]byte(queryString[start:i]), masks, urlPath)
+						check = rs.checkBytesSegment([]byte(queryString[start:i]), masks, urlPath, nil)
 					} else {
-						allowed = pv.isParamAllowedSegment(queryString[start:i], masks, urlPath)
+						check = rs.checkSegment(queryString[start:i], masks, urlPath)
 					}
-					if !allowed {
+					if admission, _ := rs.admitSegment(&tracker, check, urlPath, start, i); admission == admitReject {
 						return false
 					}
+				} else if trackPresence {
+					tracker.markPresent(rs.segmentIndex(queryString[start:i], masks.CombinedMask()), start, i)
 				}
 				paramCount++
 			}
 			start = i + 1
 		}
 	}
-	return paramCount <= MaxParamValues
+
+	if !rs.trackerSatisfied(&tracker, masks, urlPath) {
+		return false
+	}
+	return tracker.present.Contains(required)
 }
 
 // parseQuerySegment parses query segment and returns positions
-func (pv *ParamValidator) parseQuerySegment(queryString string, start, end int) (eqPos, keyStart, keyEnd, valStart, valEnd int) {
+func (rs *ruleSnapshot) parseQuerySegment(queryString string, start, end int) (eqPos, keyStart, keyEnd, valStart, valEnd int) {
 	keyStart = start
 	eqPos = -1
 
@@ -199,8 +209,17 @@ func (pi *ParamIndex) GetIndexByRange(str string, start, end int) int {
 		return -1
 	}
 
+	if idx := indexByRange(&pi.paramToIndex, str, start, end); idx != -1 || !pi.hasAliases.Load() {
+		return idx
+	}
+	return indexByRange(&pi.aliasToIndex, str, start, end)
+}
+
+// indexByRange scans name to index map for key given by byte range of str
+func indexByRange(names *sync.Map, str string, start, end int) int {
+	length := end - start
 	var result int = -1
-	pi.paramToIndex.Range(func(key, value interface{}) bool {
+	names.Range(func(key, value interface{}) bool {
 		paramName := key.(string)
 		if len(paramName) == length {
 			match := true
@@ -221,123 +240,178 @@ func (pi *ParamIndex) GetIndexByRange(str string, start, end int) int {
 }
 
 // isParamAllowedFast optimized parameter validation
-func (pv *ParamValidator) isParamAllowedFast(paramName, paramValue string, masks ParamMasks, urlPath string) bool {
-	idx := pv.compiledRules.paramIndex.GetIndex(paramName)
+func (rs *ruleSnapshot) isParamAllowedFast(paramName, paramValue string, masks ParamMasks, urlPath string) bool {
+	return rs.checkParamFast(paramName, paramValue, masks, urlPath).allowed
+}
+
+// checkParamFast validates parameter and returns its index and rule
+func (rs *ruleSnapshot) checkParamFast(paramName, paramValue string, masks ParamMasks, urlPath string) segmentCheck {
+	active := masks.CombinedMask()
+	idx := rs.lookupParamIndex(paramName, active)
 	if idx == -1 {
-		return false
+		return segmentCheck{index: -1}
 	}
 
-	if !masks.CombinedMask().GetBit(idx) {
-		return false
+	if !active.GetBit(idx) {
+		return segmentCheck{index: idx}
 	}
 
-	rule := pv.findParamRuleByIndex(idx, masks, urlPath)
-	return rule != nil && pv.isValueValidFast(rule, paramValue)
+	rule := rs.findParamRuleByIndex(idx, masks, urlPath)
+	check := segmentCheck{
+		index:   idx,
+		rule:    rule,
+		allowed: rule != nil && rs.isValueValidFast(rule, paramValue),
+	}
+	observeDeprecated(rs, paramName, paramValue, check, masks, urlPath)
+	return check
 }
 
 // findParamRuleByIndex finds rule by index without name lookup
-func (pv *ParamValidator) findParamRuleByIndex(paramIndex int, masks ParamMasks, urlPath string) *ParamRule {
+func (rs *ruleSnapshot) findParamRuleByIndex(paramIndex int, masks ParamMasks, urlPath string) *ParamRule {
+	rule, _ := rs.resolveParamRuleByIndex(paramIndex, masks, urlPath)
+	return rule
+}
+
+// resolveParamRuleByIndex finds rule by index together with the URL rule that declares it
+func (rs *ruleSnapshot) resolveParamRuleByIndex(paramIndex int, masks ParamMasks, urlPath string) (*ParamRule, *URLRule) {
 	source := masks.GetRuleSource(paramIndex)
 
 	switch source {
 	case SourceSpecificURL:
-		if mostSpecificRule := pv.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
-			return pv.findParamInURLRuleByIndex(mostSpecificRule, paramIndex)
+		if masks.variant != nil {
+			if rule := masks.variant.paramsByIndex[paramIndex]; rule != nil {
+				return rule, masks.variant
+			}
+		}
+		if mostSpecificRule := rs.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
+			return rs.findParamInURLRuleByIndex(mostSpecificRule, paramIndex), mostSpecificRule
 		}
 	case SourceURL:
-		return pv.findURLRuleForParamByIndex(paramIndex, urlPath)
+		if urlRule := rs.findURLRuleByParamIndex(paramIndex, urlPath); urlRule != nil {
+			return urlRule.paramsByIndex[paramIndex], urlRule
+		}
 	case SourceGlobal:
-		return pv.findGlobalParamByIndex(paramIndex)
+		return rs.findGlobalParamByIndex(paramIndex), nil
 	}
-	return nil
+	return nil, nil
 }
 
 // findGlobalParamByIndex finds global parameter by index (read-only)
-func (pv *ParamValidator) findGlobalParamByIndex(paramIndex int) *ParamRule {
-	return pv.compiledRules.globalParamsByIndex[paramIndex]
+func (rs *ruleSnapshot) findGlobalParamByIndex(paramIndex int) *ParamRule {
+	return rs.compiledRules.globalParamsByIndex[paramIndex]
 }
 
 // findURLRuleForParamByIndex finds URL rule by parameter index
-func (pv *ParamValidator) findURLRuleForParamByIndex(paramIndex int, urlPath string) *ParamRule {
-	rules := pv.compiledRules.urlRulesByIndex[paramIndex]
+func (rs *ruleSnapshot) findURLRuleForParamByIndex(paramIndex int, urlPath string) *ParamRule {
+	if urlRule := rs.findURLRuleByParamIndex(paramIndex, urlPath); urlRule != nil {
+		return urlRule.paramsByIndex[paramIndex]
+	}
+	return nil
+}
+
+// findURLRuleByParamIndex finds most specific matching URL rule declaring parameter index
+func (rs *ruleSnapshot) findURLRuleByParamIndex(paramIndex int, urlPath string) *URLRule {
+	rules := rs.compiledRules.urlRulesByIndex[paramIndex]
 	if len(rules) == 0 {
 		return nil
 	}
 
 	var mostSpecificRule *URLRule
 	for _, rule := range rules {
-		if pv.urlMatchesPatternUnsafe(urlPath, rule.URLPattern) {
+		if rs.urlMatchesPatternUnsafe(urlPath, rule.URLPattern) {
 			if mostSpecificRule == nil || isPatternMoreSpecific(rule.URLPattern, mostSpecificRule.URLPattern) {
 				mostSpecificRule = rule
 			}
 		}
 	}
 
-	if mostSpecificRule != nil {
-		return mostSpecificRule.paramsByIndex[paramIndex]
-	}
-	return nil
+	return mostSpecificRule
 }
 
 // findParamInURLRuleByIndex finds parameter in URL rule by index
-func (pv *ParamValidator) findParamInURLRuleByIndex(urlRule *URLRule, paramIndex int) *ParamRule {
+func (rs *ruleSnapshot) findParamInURLRuleByIndex(urlRule *URLRule, paramIndex int) *ParamRule {
 	if urlRule.paramsByIndex == nil {
-		pv.buildURLRuleParamsByIndex(urlRule)
+		rs.buildURLRuleParamsByIndex(urlRule)
 	}
 	return urlRule.paramsByIndex[paramIndex]
 }
 
 // buildURLRuleParamsByIndex builds URL rule parameters cache by index
-func (pv *ParamValidator) buildURLRuleParamsByIndex(urlRule *URLRule) {
+func (rs *ruleSnapshot) buildURLRuleParamsByIndex(urlRule *URLRule) {
 	urlRule.paramsByIndex = make(map[int]*ParamRule)
 	for paramName, paramRule := range urlRule.Params {
-		if idx := pv.compiledRules.paramIndex.GetIndex(paramName); idx != -1 {
+		if idx := rs.compiledRules.paramIndex.GetIndex(paramName); idx != -1 {
 			urlRule.paramsByIndex[idx] = paramRule
 		}
 	}
 }
 
 // isValueValidFast optimized value validation without callback panic protection for fast path
-func (pv *ParamValidator) isValueValidFast(rule *ParamRule, value string) bool {
-	return pv.isValueValidInternal(rule, value, true)
+func (rs *ruleSnapshot) isValueValidFast(rule *ParamRule, value string) bool {
+	return rs.isValueValidInternal(rule, value, true)
 }
 
 // isValueValid universal value validation function with panic protection
-func (pv *ParamValidator) isValueValid(rule *ParamRule, value string, useFast bool) bool {
-	return pv.isValueValidInternal(rule, value, useFast)
+func (rs *ruleSnapshot) isValueValid(rule *ParamRule, value string, useFast bool) bool {
+	return rs.isValueValidInternal(rule, value, useFast)
 }
 
 // isValueValidInternal internal implementation of value validation
-func (pv *ParamValidator) isValueValidInternal(rule *ParamRule, value string, useFast bool) bool {
+func (rs *ruleSnapshot) isValueValidInternal(rule *ParamRule, value string, useFast bool) bool {
+	return rs.checkValue(rule, value, useFast) == ViolationNone
+}
+
+// checkValue validates value against rule and returns violation kind
+func (rs *ruleSnapshot) checkValue(rule *ParamRule, value string, useFast bool) ViolationKind {
 	if rule == nil {
-		return false
+		return ViolationUnknownParam
 	}
+	value = rule.transformValue(value)
 
 	var result bool
+	failure := ViolationNone
 
 	switch rule.Pattern {
 	case PatternKeyOnly:
 		result = value == ""
+		failure = ViolationKeyOnlyValue
 	case PatternAny:
 		result = true
 	case PatternEnum:
-		result = pv.validateEnum(rule, value, useFast)
+		result = rs.validateEnum(rule, value, useFast)
+		failure = ViolationEnumMismatch
 	case PatternCallback:
-		result = pv.validateCallback(rule, value, useFast)
+		result = rs.validateCallback(rule, value, useFast)
+		failure = ViolationCallbackRejected
 	case "plugin":
-		result = pv.validatePlugin(rule, value, useFast)
+		result = rs.validatePlugin(rule, value, useFast)
+		failure = ViolationPluginRejected
 	default:
 		result = false
+		failure = ViolationUnknownParam
 	}
 
 	if rule.Inverted {
-		return !result
+		if result {
+			return ViolationInvertedMatch
+		}
+		return ViolationNone
 	}
-	return result
+	if !result {
+		return failure
+	}
+	return ViolationNone
 }
 
 // validateEnum validates enum pattern
-func (pv *ParamValidator) validateEnum(rule *ParamRule, value string, useFast bool) bool {
+func (rs *ruleSnapshot) validateEnum(rule *ParamRule, value string, useFast bool) bool {
+	if _, mapped := mapValue(rule.Mappings, value, rs.foldsValues(rule)); mapped {
+		return true
+	}
+	if rs.foldsValues(rule) {
+		return containsValue(rule.Values, value, true)
+	}
+
 	if useFast {
 		for i := 0; i < len(rule.Values); i++ {
 			if value == rule.Values[i] {
@@ -356,19 +430,19 @@ func (pv *ParamValidator) validateEnum(rule *ParamRule, value string, useFast bo
 }
 
 // validateCallback validates callback pattern
-func (pv *ParamValidator) validateCallback(rule *ParamRule, value string, useFast bool) bool {
-	if pv.callbackFunc == nil {
+func (rs *ruleSnapshot) validateCallback(rule *ParamRule, value string, useFast bool) bool {
+	if rs.callbackFunc == nil {
 		return false
 	}
 
 	if useFast {
-		return pv.callbackFunc(rule.Name, value)
+		return rs.callbackFunc(rule.Name, value)
 	}
-	return pv.safeCallback(rule.Name, value)
+	return rs.safeCallback(rule.Name, value)
 }
 
 // validatePlugin validates plugin pattern
-func (pv *ParamValidator) validatePlugin(rule *ParamRule, value string, useFast bool) bool {
+func (rs *ruleSnapshot) validatePlugin(rule *ParamRule, value string, useFast bool) bool {
 	if rule.CustomValidator == nil {
 		return false
 	}
@@ -376,21 +450,21 @@ func (pv *ParamValidator) validatePlugin(rule *ParamRule, value string, useFast
 	if useFast {
 		return rule.CustomValidator(value)
 	}
-	return pv.safeCustomValidator(rule.CustomValidator, value)
+	return rs.safeCustomValidator(rule.CustomValidator, value)
 }
 
 // safeCallback executes callback with panic protection
-func (pv *ParamValidator) safeCallback(paramName, value string) (result bool) {
+func (rs *ruleSnapshot) safeCallback(paramName, value string) (result bool) {
 	defer func() {
 		if r := recover(); r != nil {
 			result = false
 		}
 	}()
-	return pv.callbackFunc(paramName, value)
+	return rs.callbackFunc(paramName, value)
 }
 
 // safeCustomValidator executes custom validator with panic protection
-func (pv *ParamValidator) safeCustomValidator(validator func(string) bool, value string) (result bool) {
+func (rs *ruleSnapshot) safeCustomValidator(validator func(string) bool, value string) (result bool) {
 	defer func() {
 		if r := recover(); r != nil {
 			result = false
@@ -400,39 +474,39 @@ func (pv *ParamValidator) safeCustomValidator(validator func(string) bool, value
 }
 
 // getParamMasksForURL optimized version
-func (pv *ParamValidator) getParamMasksForURL(urlPath string) ParamMasks {
+func (rs *ruleSnapshot) getParamMasksForURL(urlPath string) ParamMasks {
 	masks := ParamMasks{
 		Global:      NewParamMask(),
 		URL:         NewParamMask(),
 		SpecificURL: NewParamMask(),
 	}
 
-	if pv.compiledRules == nil || pv.compiledRules.paramIndex == nil {
+	if rs.compiledRules == nil || rs.compiledRules.paramIndex == nil {
 		return masks
 	}
 
 	// Global parameters
-	for name := range pv.compiledRules.globalParams {
-		if idx := pv.compiledRules.paramIndex.GetIndex(name); idx != -1 {
+	for name := range rs.compiledRules.globalParams {
+		if idx := rs.compiledRules.paramIndex.GetIndex(name); idx != -1 {
 			masks.Global.SetBit(idx)
 		}
 	}
 
 	// Most specific rule
-	mostSpecificRule := pv.findMostSpecificURLRuleUnsafe(urlPath)
+	mostSpecificRule := rs.findMostSpecificURLRuleUnsafe(urlPath)
 	if mostSpecificRule != nil {
 		for name := range mostSpecificRule.Params {
-			if idx := pv.compiledRules.paramIndex.GetIndex(name); idx != -1 {
+			if idx := rs.compiledRules.paramIndex.GetIndex(name); idx != -1 {
 				masks.SpecificURL.SetBit(idx)
 			}
 		}
 	}
 
 	// URL rules - INCLUDE all parameters, but check priorities during validation
-	for pattern, urlRule := range pv.compiledRules.urlRules {
-		if pv.urlMatchesPatternUnsafe(urlPath, pattern) {
+	for pattern, urlRule := range rs.compiledRules.urlRules {
+		if rs.urlMatchesPatternUnsafe(urlPath, pattern) {
 			for name := range urlRule.Params {
-				if idx := pv.compiledRules.paramIndex.GetIndex(name); idx != -1 {
+				if idx := rs.compiledRules.paramIndex.GetIndex(name); idx != -1 {
 					masks.URL.SetBit(idx)
 				}
 			}
@@ -443,56 +517,19 @@ func (pv *ParamValidator) getParamMasksForURL(urlPath string) ParamMasks {
 }
 
 // findParamRuleByMasks finds rule considering priorities using masks
-func (pv *ParamValidator) findParamRuleByMasks(paramName string, masks ParamMasks, urlPath string) *ParamRule {
-	if pv.compiledRules == nil {
+func (rs *ruleSnapshot) findParamRuleByMasks(paramName string, masks ParamMasks, urlPath string) *ParamRule {
+	if rs.compiledRules == nil {
 		return nil
 	}
 
-	idx := pv.compiledRules.paramIndex.GetIndex(paramName)
-	if idx == -1 {
+	active := masks.CombinedMask()
+	idx := rs.lookupParamIndex(paramName, active)
+	if idx == -1 || !active.GetBit(idx) {
 		return nil
 	}
 
-	// Check in priority order: SpecificURL -> URL -> Global
-	if masks.SpecificURL.GetBit(idx) {
-		if mostSpecificRule := pv.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
-			if rule, exists := mostSpecificRule.Params[paramName]; exists {
-				return rule
-			}
-		}
-	}
-
-	if masks.URL.GetBit(idx) {
-		if rule := pv.findURLRuleForParam(paramName, urlPath); rule != nil {
-			return rule
-		}
-	}
-
-	if masks.Global.GetBit(idx) {
-		return pv.compiledRules.globalParams[paramName]
-	}
-
-	return nil
-}
-
-// findURLRuleForParam finds URL rule for parameter
-func (pv *ParamValidator) findURLRuleForParam(paramName, urlPath string) *ParamRule {
-	var mostSpecificURLRule *URLRule
-
-	for pattern, urlRule := range pv.compiledRules.urlRules {
-		if pv.urlMatchesPatternUnsafe(urlPath, pattern) {
-			if _, exists := urlRule.Params[paramName]; exists {
-				if mostSpecificURLRule == nil || isPatternMoreSpecific(pattern, mostSpecificURLRule.URLPattern) {
-					mostSpecificURLRule = urlRule
-				}
-			}
-		}
-	}
-
-	if mostSpecificURLRule != nil {
-		return mostSpecificURLRule.Params[paramName]
-	}
-	return nil
+	// Priority order: SpecificURL -> URL -> Global
+	return rs.findParamRuleByIndex(idx, masks, urlPath)
 }
 
 func isPatternMoreSpecific(pattern1, pattern2 string) bool {
@@ -507,123 +544,208 @@ func isPatternMoreSpecific(pattern1, pattern2 string) bool {
 }
 
 // isParamAllowedWithMasks checks parameter using mask system
-func (pv *ParamValidator) isParamAllowedWithMasks(paramName, paramValue string, masks ParamMasks, urlPath string) bool {
-	rule := pv.findParamRuleByMasks(paramName, masks, urlPath)
+func (rs *ruleSnapshot) isParamAllowedWithMasks(paramName, paramValue string, masks ParamMasks, urlPath string) bool {
+	rule := rs.findParamRuleByMasks(paramName, masks, urlPath)
 	if rule == nil {
 		return false
 	}
 
-	if masks.SpecificURL.GetBit(pv.compiledRules.paramIndex.GetIndex(paramName)) {
-		if mostSpecificRule := pv.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
-			if specificRule, exists := mostSpecificRule.Params[paramName]; exists {
-				return pv.isValueValid(specificRule, paramValue, false)
-			}
-		}
-	}
-
-	return pv.isValueValid(rule, paramValue, false)
+	return rs.isValueValid(rule, paramValue, false)
 }
 
 // FilterQueryBytes filters query parameters into provided buffer
 // Returns slice of buffer containing filtered parameters (zero allocations)
-// buffer must have sufficient capacity (at least len(queryString))
+// buffer must have sufficient capacity (at least len(queryString)), parameters written back renamed, mapped,
+// transformed or repaired additionally need room for their new form, up to three times its length when bytes get percent-encoded,
+// canonical mode additionally needs room for canonical form of filtered parameters, up to three times their length likewise
+// Returns nil if required parameters are missing after filtering or filtered query does not fit into buffer
 func (pv *ParamValidator) FilterQueryBytes(urlPath, queryBytes, buffer []byte) []byte {
-	if !pv.initialized.Load() || len(queryBytes) == 0 {
+	return pv.FilterQueryBytesWithScratch(urlPath, queryBytes, buffer, nil)
+}
+
+// FilterQueryBytesWithScratch filters query parameters into provided buffer
+// scratch is used for decoding keys and values when decoding is enabled
+// and must hold the longest query segment to keep zero allocations
+// In report-only mode query that filtering would change is reported and copied to buffer unchanged
+func (pv *ParamValidator) FilterQueryBytesWithScratch(urlPath, queryBytes, buffer, scratch []byte) []byte {
+	if !pv.initialized.Load() {
+		return nil
+	}
+	rs := pv.current()
+	filtered := rs.filterQueryBytes(urlPath, queryBytes, buffer, scratch)
+	if rs.reportObserver == nil || bytes.Equal(filtered, queryBytes) {
+		return filtered
+	}
+	rs.reportQuery(ReportFilter, string(urlPath), string(queryBytes), string(filtered))
+	if cap(buffer) < len(queryBytes) {
 		return nil
 	}
+	return append(buffer[:0], queryBytes...)
+}
 
-	pv.mu.RLock()
-	defer pv.mu.RUnlock()
+// filterQueryBytes filters query parameters into provided buffer enforcing verdict
+func (rs *ruleSnapshot) filterQueryBytes(urlPath, queryBytes, buffer, scratch []byte) []byte {
+	if len(queryBytes) == 0 {
+		return nil
+	}
 
-	if len(urlPath) > MaxURLLength || len(queryBytes) > MaxURLLength {
+	if len(urlPath) > MaxURLLength || len(queryBytes) > MaxURLLength || rs.compiledRules == nil {
 		return nil
 	}
 
 	urlPathStr := string(urlPath)
-	masks := pv.createParamMasks(urlPathStr)
+	masks := rs.createParamMasks(urlPathStr)
+	rs.selectVariantBytes(&masks, urlPathStr, queryBytes)
 
-	return pv.filterQueryParamsToBuffer(queryBytes, masks, urlPathStr, buffer, true)
+	return rs.filterQueryParamsToBuffer(queryBytes, masks, urlPathStr, buffer, scratch, true)
 }
 
 // filterQueryParamsToBuffer filters into provided buffer (fully []byte)
-func (pv *ParamValidator) filterQueryParamsToBuffer(queryBytes []byte, masks ParamMasks, urlPath string, buffer []byte, useBytes bool) []byte {
+func (rs *ruleSnapshot) filterQueryParamsToBuffer(queryBytes []byte, masks ParamMasks, urlPath string, buffer, scratch []byte, useBytes bool) []byte {
 	if cap(buffer) < len(queryBytes) {
 		return nil
 	}
 
+	required := rs.requiredMaskWithVariant(masks, urlPath)
 	result := buffer[:0]
 	firstParam := true
+	var tracker queryTracker
+	if rs.compiledRules.needsTrackerState {
+		tracker.state = &trackerState{}
+	}
+	rs.initQueryTrackerBytes(&tracker, queryBytes, masks, scratch)
 	start := 0
 
 	for i := 0; i <= len(queryBytes); i++ {
 		if i == len(queryBytes) || queryBytes[i] == '&' {
 			if start < i {
-				var allowed bool
+				var check segmentCheck
 				if useBytes {
-					allowed = pv.isParamAllowedBytesSegment(queryBytes[start:i], masks, urlPath)
+					check = rs.checkBytesSegment(queryBytes[start:i], masks, urlPath, scratch)
 				} else {
-					allowed = pv.isParamAllowedSegment(string(queryBytes[start:i]), masks, urlPath)
+					check = rs.checkSegment(string(queryBytes[start:i]), masks, urlPath)
 				}
 
-				if allowed {
+				// Segment goes past separator, rewritten one is built right there so it never leaves caller's buffer
+				offset := len(result)
+				if !firstParam {
+					offset++
+				}
+				at := min(offset, cap(buffer))
+				segment := queryBytes[start:i]
+				rewriteCheck := check
+				rewriteCheck.rule = rs.conditionedRule(&tracker, check, urlPath)
+				rewritten, isRewritten := appendRewrittenSegment(rs, buffer[at:at], segment, rewriteCheck)
+				if isRewritten {
+					segment, check.allowed = rewritten, true
+				}
+
+				if admission, _ := rs.admitSegment(&tracker, check, urlPath, start, i); admission == admitAccept {
+					// Rewritten segment outgrowing buffer was moved off it and must not be used
+					if offset+len(segment) > cap(buffer) {
+						return nil
+					}
 					if !firstParam {
 						result = append(result, '&')
 					} else {
 						firstParam = false
 					}
-					result = append(result, queryBytes[start:i]...)
+					if isRewritten {
+						result = result[:offset+len(segment)]
+					} else {
+						result = append(result, segment...)
+					}
 				}
 			}
 			start = i + 1
 		}
 	}
 
-	if len(result) == 0 {
+	result = rs.dropUnsatisfiedClauses(&tracker, result, masks, urlPath, scratch)
+	if len(result) == 0 || !tracker.present.Contains(required) {
+		return nil
+	}
+	if !rs.trackerSatisfied(&tracker, masks, urlPath) {
 		return nil
 	}
+	if rs.canonicalMode != CanonicalOff {
+		// Canonical query is built past the filtered one and then moved to buffer start
+		if cap(buffer)-len(result) < canonicalQueryLen(result, rs.decodingMode) {
+			return nil
+		}
+		canonical, ok := rs.appendCanonicalQuery(result[len(result):], result, masks, urlPath, scratch)
+		if !ok {
+			return nil
+		}
+		result = buffer[:copy(buffer[:len(canonical)], canonical)]
+	}
 	return result
 }
 
 // ValidateQueryBytes validates query parameters bytes for URL path
 // Zero-allocs version for high-performance scenarios
 func (pv *ParamValidator) ValidateQueryBytes(urlPath, queryBytes []byte) bool {
-	if !pv.initialized.Load() || len(urlPath) == 0 {
+	return pv.ValidateQueryBytesWithScratch(urlPath, queryBytes, nil)
+}
+
+// ValidateQueryBytesWithScratch validates query parameters bytes for URL path
+// scratch is used for decoding keys and values when decoding is enabled
+// and must hold the longest query segment to keep zero allocations
+// In report-only mode rejected query is reported and accepted
+func (pv *ParamValidator) ValidateQueryBytesWithScratch(urlPath, queryBytes, scratch []byte) bool {
+	if !pv.initialized.Load() {
 		return false
 	}
+	rs := pv.current()
+	if valid := rs.validateQueryBytes(urlPath, queryBytes, scratch); valid || rs.reportObserver == nil {
+		return valid
+	}
+	rs.reportQuery(ReportValidate, string(urlPath), string(queryBytes), "")
+	return true
+}
 
-	return pv.withSafeAccess(func() bool {
-		if len(urlPath) > MaxURLLength || len(queryBytes) > MaxURLLength {
-			return false
-		}
+// validateQueryBytes validates query parameters bytes for URL path enforcing verdict
+func (rs *ruleSnapshot) validateQueryBytes(urlPath, queryBytes, scratch []byte) bool {
+	if len(urlPath) == 0 {
+		return false
+	}
 
-		if len(queryBytes) == 0 {
-			return true
-		}
+	if len(urlPath) > MaxURLLength || len(queryBytes) > MaxURLLength {
+		return false
+	}
 
-		// Convert urlPath to string once (this allocation is necessary for URL matching)
-		urlPathStr := string(urlPath)
-		masks := pv.createParamMasks(urlPathStr)
+	// Convert urlPath to string once (this allocation is necessary for URL matching)
+	urlPathStr := string(urlPath)
 
-		if pv.isAllowAllParamsMasks(masks) {
-			return true
-		}
+	if len(queryBytes) == 0 {
+		return rs.emptyQueryAllowed(urlPathStr)
+	}
 
-		if masks.CombinedMask().IsEmpty() {
-			return false
-		}
+	masks := rs.createParamMasks(urlPathStr)
+	rs.selectVariantBytes(&masks, urlPathStr, queryBytes)
 
-		// Use bytes version without converting queryBytes to string
-		return pv.validateQueryParamsBytes(queryBytes, masks, urlPathStr)
-	})
+	if masks.CombinedMask().IsEmpty() {
+		return false
+	}
+
+	// Use bytes version without converting queryBytes to string
+	return rs.validateQueryParamsBytes(queryBytes, masks, urlPathStr, scratch)
 }
 
 // validateQueryParamsBytes validates query parameters in []byte form without allocations
-func (pv *ParamValidator) validateQueryParamsBytes(queryBytes []byte, masks ParamMasks, urlPath string) bool {
+func (rs *ruleSnapshot) validateQueryParamsBytes(queryBytes []byte, masks ParamMasks, urlPath string, scratch []byte) bool {
 	if len(queryBytes) == 0 {
-		return true
+		return rs.emptyQueryAllowed(urlPath)
 	}
 
-	allowAll := pv.isAllowAllParamsMasks(masks)
+	required := rs.requiredMaskWithVariant(masks, urlPath)
+	allowAll := rs.isAllowAllParamsMasks(masks)
+	trackPresence := !required.IsEmpty() || rs.compiledRules.hasClauses
+	var tracker queryTracker
+	if rs.compiledRules.needsTrackerState {
+		tracker.state = &trackerState{}
+	}
+	rs.initQueryTrackerBytes(&tracker, queryBytes, masks, scratch)
 	start := 0
 	paramCount := 0
 
@@ -631,31 +753,43 @@ func (pv *ParamValidator) validateQueryParamsBytes(queryBytes []byte, masks Para
 		if i == len(queryBytes) || queryBytes[i] == '&' {
 			if start < i && paramCount < MaxParamValues {
 				if !allowAll {
-					if !pv.isParamAllowedBytesSegment(queryBytes[start:i], masks, urlPath) {
+					check := rs.checkBytesSegment(queryBytes[start:i], masks, urlPath, scratch)
+					if admission, _ := rs.admitSegment(&tracker, check, urlPath, start, i); admission == admitReject {
 						return false
 					}
+				} else if trackPresence {
+					tracker.markPresent(rs.segmentIndexBytes(queryBytes[start:i], masks.CombinedMask(), scratch), start, i)
 				}
 				paramCount++
 			}
 			start = i + 1
 		}
 	}
-	return paramCount <= MaxParamValues
+
+	if !rs.trackerSatisfied(&tracker, masks, urlPath) {
+		return false
+	}
+	return tracker.present.Contains(required)
 }
 
 // createParamMasks creates parameter masks for URL path
-func (pv *ParamValidator) createParamMasks(urlPath string) ParamMasks {
+func (rs *ruleSnapshot) createParamMasks(urlPath string) ParamMasks {
 	masks := ParamMasks{
 		Global:      NewParamMask(),
 		URL:         NewParamMask(),
 		SpecificURL: NewParamMask(),
 	}
-	pv.fillParamMasksDirect(&masks, urlPath)
+	rs.fillParamMasksDirect(&masks, urlPath)
 	return masks
 }
 
 // isParamAllowedBytesSegment checks segment in []byte form
-func (pv *ParamValidator) isParamAllowedBytesSegment(segment []byte, masks ParamMasks, urlPath string) bool {
+func (rs *ruleSnapshot) isParamAllowedBytesSegment(segment []byte, masks ParamMasks, urlPath string, scratch []byte) bool {
+	return rs.checkBytesSegment(segment, masks, urlPath, scratch).allowed
+}
+
+// checkBytesSegment checks segment in []byte form and returns parameter index and rule
+func (rs *ruleSnapshot) checkBytesSegment(segment []byte, masks ParamMasks, urlPath string, scratch []byte) segmentCheck {
 	eqPos := -1
 	for i := 0; i < len(segment); i++ {
 		if segment[i] == '=' {
@@ -673,28 +807,47 @@ func (pv *ParamValidator) isParamAllowedBytesSegment(segment []byte, masks Param
 		valueBytes = segment[eqPos+1:]
 	}
 
-	idx := pv.compiledRules.paramIndex.GetIndexByBytes(keyBytes)
+	if rs.decodingMode != DecodeNone {
+		var ok bool
+		if keyBytes, valueBytes, ok = rs.decodeKeyValueBytes(keyBytes, valueBytes, scratch); !ok {
+			return segmentCheck{index: -1}
+		}
+	}
+
+	active := masks.CombinedMask()
+	idx := rs.lookupParamIndexBytes(keyBytes, active)
 	if idx == -1 {
-		return false
+		return segmentCheck{index: -1}
 	}
 
-	if !masks.CombinedMask().GetBit(idx) {
-		return false
+	if !active.GetBit(idx) {
+		return segmentCheck{index: idx}
 	}
 
-	rule := pv.findParamRuleByIndex(idx, masks, urlPath)
+	rule := rs.findParamRuleByIndex(idx, masks, urlPath)
 	if rule == nil {
-		return false
+		return segmentCheck{index: idx}
 	}
 
-	return pv.isValueValidBytesFast(rule, valueBytes)
+	check := segmentCheck{
+		index:   idx,
+		rule:    rule,
+		allowed: rs.isValueValidBytesFast(rule, valueBytes),
+	}
+	observeDeprecated(rs, keyBytes, valueBytes, check, masks, urlPath)
+	return check
 }
 
-func (pv *ParamValidator) isValueValidBytesFast(rule *ParamRule, valueBytes []byte) bool {
+func (rs *ruleSnapshot) isValueValidBytesFast(rule *ParamRule, valueBytes []byte) bool {
 	if rule == nil {
 		return false
 	}
 
+	var transformBuf [128]byte
+	if len(rule.Transforms) > 0 {
+		valueBytes = appendTransformed(transformBuf[:0], valueBytes, rule.Transforms)
+	}
+
 	var result bool
 
 	switch rule.Pattern {
@@ -703,7 +856,14 @@ func (pv *ParamValidator) isValueValidBytesFast(rule *ParamRule, valueBytes []by
 	case PatternAny:
 		result = true
 	case PatternEnum:
-		result = false
+		_, result = mapValue(rule.Mappings, valueBytes, rs.foldsValues(rule))
+		if result {
+			break
+		}
+		if rs.foldsValues(rule) {
+			result = containsValue(rule.Values, valueBytes, true)
+			break
+		}
 		for _, allowedValue := range rule.Values {
 			if bytesEqual(valueBytes, []byte(allowedValue)) {
 				result = true
@@ -711,9 +871,9 @@ func (pv *ParamValidator) isValueValidBytesFast(rule *ParamRule, valueBytes []by
 			}
 		}
 	case PatternCallback:
-		if pv.callbackFunc != nil {
+		if rs.callbackFunc != nil {
 			valueStr := string(valueBytes)
-			result = pv.callbackFunc(rule.Name, valueStr)
+			result = rs.callbackFunc(rule.Name, valueStr)
 		} else {
 			result = false
 		}
@@ -735,46 +895,66 @@ func (pv *ParamValidator) isValueValidBytesFast(rule *ParamRule, valueBytes []by
 }
 
 // findMostSpecificURLRuleUnsafe finds most specific matching URL rule
-func (pv *ParamValidator) findMostSpecificURLRuleUnsafe(urlPath string) *URLRule {
-	if pv.urlMatcher == nil {
+func (rs *ruleSnapshot) findMostSpecificURLRuleUnsafe(urlPath string) *URLRule {
+	if rs.urlMatcher == nil {
 		return nil
 	}
-	return pv.urlMatcher.GetMostSpecificRule(urlPath)
+	return rs.urlMatcher.mostSpecificRule(urlPath)
 }
 
 // urlMatchesPatternUnsafe checks if URL path matches pattern
-func (pv *ParamValidator) urlMatchesPatternUnsafe(urlPath, pattern string) bool {
+func (rs *ruleSnapshot) urlMatchesPatternUnsafe(urlPath, pattern string) bool {
 	return urlMatchesPattern(urlPath, pattern)
 }
 
 // ValidateParam validates single parameter value for specific URL path
+// In report-only mode rejected parameter is reported and accepted
 func (pv *ParamValidator) ValidateParam(urlPath, paramName, paramValue string) bool {
-	return pv.withSafeAccess(func() bool {
-		if urlPath == "" || paramName == "" {
-			return false
-		}
+	if !pv.initialized.Load() || urlPath == "" || paramName == "" {
+		return false
+	}
 
-		if len(urlPath) > MaxURLLength {
-			return false
-		}
+	if len(urlPath) > MaxURLLength {
+		return false
+	}
 
-		return pv.validateParamUnsafe(urlPath, paramName, paramValue)
-	})
+	rs := pv.current()
+	if valid := rs.validateParamUnsafe(urlPath, paramName, paramValue); valid || rs.reportObserver == nil {
+		return valid
+	}
+	rs.reportParam(urlPath, paramName, paramValue)
+	return true
 }
 
 // validateParamUnsafe validates single parameter using masks
-func (pv *ParamValidator) validateParamUnsafe(urlPath, paramName, paramValue string) bool {
-	if pv.compiledRules == nil {
+func (rs *ruleSnapshot) validateParamUnsafe(urlPath, paramName, paramValue string) bool {
+	if rs.compiledRules == nil {
 		return false
 	}
 
-	masks := pv.getParamMasksForURL(urlPath)
-	return pv.isParamAllowedWithMasks(paramName, paramValue, masks, urlPath)
+	masks := rs.getParamMasksForURL(urlPath)
+	return rs.isParamAllowedWithMasks(paramName, paramValue, masks, urlPath)
 }
 
 // FilterURL optimized version
+// Returns empty string if required parameters are missing after filtering
+// In report-only mode URL that filtering would change is reported and returned unchanged
 func (pv *ParamValidator) FilterURL(fullURL string) string {
-	if !pv.initialized.Load() || fullURL == "" {
+	if !pv.initialized.Load() {
+		return fullURL
+	}
+	rs := pv.current()
+	filtered := rs.filterURL(fullURL)
+	if rs.reportObserver == nil || filtered == fullURL {
+		return filtered
+	}
+	rs.reportURL(ReportFilter, fullURL, filtered)
+	return fullURL
+}
+
+// filterURL filters complete URL enforcing verdict
+func (rs *ruleSnapshot) filterURL(fullURL string) string {
+	if fullURL == "" {
 		return fullURL
 	}
 
@@ -787,33 +967,65 @@ func (pv *ParamValidator) FilterURL(fullURL string) string {
 		return fullURL
 	}
 
-	pv.mu.RLock()
-	defer pv.mu.RUnlock()
-
-	return pv.normalizeURLFast(u)
+	return rs.normalizeURLFast(u)
 }
 
 // normalizeURLFast fast normalization
-func (pv *ParamValidator) normalizeURLFast(u *url.URL) string {
+// Returns empty string if required parameters are missing after filtering
+func (rs *ruleSnapshot) normalizeURLFast(u *url.URL) string {
+	required := rs.requiredMaskForURL(u.Path)
+
 	if u.RawQuery == "" {
+		if !rs.emptyQueryAllowed(u.Path) {
+			return ""
+		}
 		return u.String()
 	}
 
-	if pv.compiledRules == nil || pv.compiledRules.paramIndex == nil {
+	if rs.compiledRules == nil || rs.compiledRules.paramIndex == nil {
 		return u.Path
 	}
 
-	masks := pv.getParamMasksForURL(u.Path)
+	masks := rs.getParamMasksForURL(u.Path)
+	rs.selectVariant(&masks, u.Path, u.RawQuery)
+	required = rs.requiredMaskWithVariant(masks, u.Path)
 
-	if idx := pv.compiledRules.paramIndex.GetIndex(PatternAll); idx != -1 && masks.CombinedMask().GetBit(idx) {
+	if idx := rs.compiledRules.paramIndex.GetIndex(PatternAll); idx != -1 && masks.CombinedMask().GetBit(idx) {
+		var tracker queryTracker
+		if rs.compiledRules.needsTrackerState {
+			tracker.state = &trackerState{}
+		}
+		rs.trackQueryPresence(&tracker, u.RawQuery, masks.CombinedMask())
+		if rs.compiledRules.hasClauses {
+			u.RawQuery = string(rs.dropUnsatisfiedClauses(&tracker, []byte(u.RawQuery), masks, u.Path, nil))
+			if !rs.clausesSatisfied(&tracker, u.Path) {
+				return ""
+			}
+		}
+		if !tracker.present.Contains(required) {
+			return ""
+		}
+		if rs.canonicalMode != CanonicalOff {
+			canonical, ok := rs.appendCanonicalQuery(nil, []byte(u.RawQuery), masks, u.Path, nil)
+			if !ok {
+				return ""
+			}
+			u.RawQuery = string(canonical)
+		}
 		return u.String()
 	}
 
 	if masks.CombinedMask().IsEmpty() {
+		if !required.IsEmpty() {
+			return ""
+		}
 		return u.Path
 	}
 
-	filteredQuery := pv.filterQueryParamsFast(u.RawQuery, masks, u.Path)
+	filteredQuery, complete := rs.filterQueryParamsFast(u.RawQuery, masks, u.Path)
+	if !complete {
+		return ""
+	}
 	if filteredQuery == "" {
 		return u.Path
 	}
@@ -840,38 +1052,75 @@ func buildNormalizedURL(path, query string) string {
 }
 
 // filterQueryParamsFast fast parameter filtering
-func (pv *ParamValidator) filterQueryParamsFast(queryString string, masks ParamMasks, urlPath string) string {
+// Reports false if filtered query lacks required parameters
+func (rs *ruleSnapshot) filterQueryParamsFast(queryString string, masks ParamMasks, urlPath string) (string, bool) {
+	required := rs.requiredMaskWithVariant(masks, urlPath)
 	if queryString == "" {
-		return ""
+		return "", rs.emptyQueryAllowed(urlPath)
 	}
 
 	var buf [1024]byte
 	result := buf[:0]
 	firstParam := true
+	var tracker queryTracker
+	if rs.compiledRules.needsTrackerState {
+		tracker.state = &trackerState{}
+	}
+	rs.initQueryTracker(&tracker, queryString, masks)
+	var rewriteBuf [128]byte
 
 	start := 0
 	for i := 0; i <= len(queryString); i++ {
 		if i == len(queryString) || queryString[i] == '&' {
 			if start < i {
 				segment := queryString[start:i]
-				if pv.isParamAllowedSegment(segment, masks, urlPath) {
+				check := rs.checkSegment(segment, masks, urlPath)
+				rewriteCheck := check
+				rewriteCheck.rule = rs.conditionedRule(&tracker, check, urlPath)
+				rewritten, isRewritten := appendRewrittenSegment(rs, rewriteBuf[:0], segment, rewriteCheck)
+				check.allowed = check.allowed || isRewritten
+				if admission, _ := rs.admitSegment(&tracker, check, urlPath, start, i); admission == admitAccept {
 					if !firstParam {
 						result = append(result, '&')
 					} else {
 						firstParam = false
 					}
-					result = append(result, segment...)
+					if isRewritten {
+						result = append(result, rewritten...)
+					} else {
+						result = append(result, segment...)
+					}
 				}
 			}
 			start = i + 1
 		}
 	}
 
-	return string(result)
+	result = rs.dropUnsatisfiedClauses(&tracker, result, masks, urlPath, nil)
+	if !tracker.present.Contains(required) {
+		return "", false
+	}
+	if !rs.trackerSatisfied(&tracker, masks, urlPath) {
+		return "", false
+	}
+	if rs.canonicalMode != CanonicalOff {
+		var canonicalBuf [1024]byte
+		canonical, ok := rs.appendCanonicalQuery(canonicalBuf[:0], result, masks, urlPath, nil)
+		if !ok {
+			return "", false
+		}
+		return string(canonical), true
+	}
+	return string(result), true
+}
+
+func (rs *ruleSnapshot) isParamAllowedSegment(segment string, masks ParamMasks, urlPath string) bool {
+	return rs.checkSegment(segment, masks, urlPath).allowed
 }
 
-func (pv *ParamValidator) isParamAllowedSegment(segment string, masks ParamMasks, urlPath string) bool {
-	eqPos, _, _, _, _ := pv.parseQuerySegment(segment, 0, len(segment))
+// checkSegment checks query segment and returns parameter index and rule
+func (rs *ruleSnapshot) checkSegment(segment string, masks ParamMasks, urlPath string) segmentCheck {
+	eqPos, _, _, _, _ := rs.parseQuerySegment(segment, 0, len(segment))
 
 	var key, value string
 	if eqPos == -1 {
@@ -882,75 +1131,107 @@ func (pv *ParamValidator) isParamAllowedSegment(segment string, masks ParamMasks
 		value = segment[eqPos+1:]
 	}
 
-	return pv.isParamAllowedFast(key, value, masks, urlPath)
+	if rs.decodingMode != DecodeNone {
+		var ok bool
+		if key, value, ok = rs.decodeKeyValue(key, value); !ok {
+			return segmentCheck{index: -1}
+		}
+	}
+
+	return rs.checkParamFast(key, value, masks, urlPath)
 }
 
 // FilterQuery filters query parameters string according to validation rules
+// Returns empty string if required parameters are missing after filtering
+// In report-only mode query that filtering would change is reported and returned unchanged
 func (pv *ParamValidator) FilterQuery(urlPath, queryString string) string {
-	if !pv.initialized.Load() || queryString == "" {
+	if !pv.initialized.Load() {
 		return ""
 	}
+	rs := pv.current()
+	filtered := rs.filterQuery(urlPath, queryString)
+	if rs.reportObserver == nil || filtered == queryString {
+		return filtered
+	}
+	rs.reportQuery(ReportFilter, urlPath, queryString, filtered)
+	return queryString
+}
 
-	pv.mu.RLock()
-	defer pv.mu.RUnlock()
+// filterQuery filters query parameters string enforcing verdict
+func (rs *ruleSnapshot) filterQuery(urlPath, queryString string) string {
+	if queryString == "" {
+		return ""
+	}
 
-	if len(urlPath) > MaxURLLength {
+	if len(urlPath) > MaxURLLength || rs.compiledRules == nil {
 		return ""
 	}
 
-	return pv.filterQueryParamsFast(queryString, pv.getParamMasksForURL(urlPath), urlPath)
+	masks := rs.getParamMasksForURL(urlPath)
+	rs.selectVariant(&masks, urlPath, queryString)
+	filteredQuery, _ := rs.filterQueryParamsFast(queryString, masks, urlPath)
+	return filteredQuery
 }
 
 // ValidateQuery validates query parameters string for URL path
+// In report-only mode rejected query is reported and accepted
 func (pv *ParamValidator) ValidateQuery(urlPath, queryString string) bool {
-	if !pv.initialized.Load() || urlPath == "" {
+	if !pv.initialized.Load() {
 		return false
 	}
+	rs := pv.current()
+	if valid := rs.validateQuery(urlPath, queryString); valid || rs.reportObserver == nil {
+		return valid
+	}
+	rs.reportQuery(ReportValidate, urlPath, queryString, "")
+	return true
+}
 
-	return pv.withSafeAccess(func() bool {
-		if len(urlPath) > MaxURLLength {
-			return false
-		}
+// validateQuery validates query parameters string for URL path enforcing verdict
+func (rs *ruleSnapshot) validateQuery(urlPath, queryString string) bool {
+	if urlPath == "" {
+		return false
+	}
 
-		if queryString == "" {
-			return true
-		}
+	if len(urlPath) > MaxURLLength {
+		return false
+	}
 
-		if len(queryString) > MaxURLLength {
-			return false
-		}
+	if queryString == "" {
+		return rs.emptyQueryAllowed(urlPath)
+	}
 
-		masks := pv.createParamMasks(urlPath)
+	if len(queryString) > MaxURLLength {
+		return false
+	}
 
-		if pv.isAllowAllParamsMasks(masks) {
-			return true
-		}
+	masks := rs.createParamMasks(urlPath)
+	rs.selectVariant(&masks, urlPath, queryString)
 
-		if masks.CombinedMask().IsEmpty() {
-			return false
-		}
+	if masks.CombinedMask().IsEmpty() {
+		return false
+	}
 
-		return pv.validateQueryParams(queryString, masks, urlPath, false)
-	})
+	return rs.validateQueryParams(queryString, masks, urlPath, false)
 }
 
 // fillParamMasksDirect fills masks directly without extra allocations
-func (pv *ParamValidator) fillParamMasksDirect(masks *ParamMasks, urlPath string) {
-	if pv.compiledRules == nil || pv.compiledRules.paramIndex == nil {
+func (rs *ruleSnapshot) fillParamMasksDirect(masks *ParamMasks, urlPath string) {
+	if rs.compiledRules == nil || rs.compiledRules.paramIndex == nil {
 		return
 	}
 
 	// Global parameters - use pre-calculated mask
-	masks.Global = pv.compiledRules.globalParamsMask
+	masks.Global = rs.compiledRules.globalParamsMask
 
 	// Most specific rule
-	if mostSpecificRule := pv.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
+	if mostSpecificRule := rs.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
 		masks.SpecificURL = mostSpecificRule.ParamMask
 	}
 
 	// URL rules
-	for pattern, urlRule := range pv.compiledRules.urlRules {
-		if pv.urlMatchesPatternUnsafe(urlPath, pattern) {
+	for pattern, urlRule := range rs.compiledRules.urlRules {
+		if rs.urlMatchesPatternUnsafe(urlPath, pattern) {
 			filteredMask := urlRule.ParamMask.Difference(masks.SpecificURL)
 			masks.URL = masks.URL.Union(filteredMask)
 		}
@@ -961,82 +1242,80 @@ func (pv *ParamValidator) fillParamMasksDirect(masks *ParamMasks, urlPath string
 func (pv *ParamValidator) ClearRules() {
 	pv.mu.Lock()
 	defer pv.mu.Unlock()
-	pv.clearUnsafe()
-}
-
-// clearUnsafe resets all rules without locking
-func (pv *ParamValidator) clearUnsafe() {
-	if pv.parser != nil {
-		pv.parser.ClearCache()
-	}
-
-	pv.globalParams = make(map[string]*ParamRule)
-	pv.urlRules = make(map[string]*URLRule)
-	pv.compiledRules = &CompiledRules{
-		globalParams: make(map[string]*ParamRule),
-		urlRules:     make(map[string]*URLRule),
-		paramIndex:   NewParamIndex(),
-	}
-	if pv.paramIndex != nil {
-		pv.paramIndex.Clear()
-	}
-	pv.urlMatcher.ClearRules()
+	next, _ := pv.buildSnapshotUnsafe("")
+	pv.publishUnsafe(next, RuleInfo{})
 }
 
 // copyParamRuleUnsafe creates a deep copy of ParamRule
-func (pv *ParamValidator) copyParamRuleUnsafe(rule *ParamRule) *ParamRule {
+func (rs *ruleSnapshot) copyParamRuleUnsafe(rule *ParamRule) *ParamRule {
 	if rule == nil {
 		return nil
 	}
 
-	ruleCopy := &ParamRule{
-		Name:            rule.Name,
-		Pattern:         rule.Pattern,
-		CustomValidator: rule.CustomValidator,
-		Inverted:        rule.Inverted,
-	}
+	ruleCopy := *rule
 
 	if rule.Values != nil {
 		ruleCopy.Values = make([]string, len(rule.Values))
 		copy(ruleCopy.Values, rule.Values)
 	}
 
-	return ruleCopy
+	if rule.Aliases != nil {
+		ruleCopy.Aliases = make([]string, len(rule.Aliases))
+		copy(ruleCopy.Aliases, rule.Aliases)
+	}
+
+	if rule.Transforms != nil {
+		ruleCopy.Transforms = make([]ValueTransform, len(rule.Transforms))
+		copy(ruleCopy.Transforms, rule.Transforms)
+	}
+
+	if rule.Mappings != nil {
+		ruleCopy.Mappings = make([]ValueMapping, len(rule.Mappings))
+		copy(ruleCopy.Mappings, rule.Mappings)
+	}
+
+	return &ruleCopy
 }
 
 // ParseRules parses and loads validation rules from string
 func (pv *ParamValidator) ParseRules(rulesStr string) error {
+	return pv.ParseRulesWithInfo(rulesStr, RuleInfo{})
+}
+
+// ParseRulesWithInfo parses and loads validation rules recording author and comment in history
+func (pv *ParamValidator) ParseRulesWithInfo(rulesStr string, info RuleInfo) error {
 	if !pv.initialized.Load() {
 		return fmt.Errorf("validator not initialized")
 	}
 
-	if rulesStr == "" {
-		pv.mu.Lock()
-		defer pv.mu.Unlock()
-		pv.clearUnsafe()
-		return nil
-	}
-
-	if err := pv.checkSize(rulesStr, MaxRulesSize, "rules string"); err != nil {
-		return err
+	if rulesStr != "" {
+		if err := pv.checkSize(rulesStr, MaxRulesSize, "rules string"); err != nil {
+			return err
+		}
 	}
 
 	pv.mu.Lock()
 	defer pv.mu.Unlock()
-
-	if pv.parser != nil {
-		pv.parser.ClearCache()
+	next, err := pv.buildSnapshotUnsafe(rulesStr)
+	if err != nil {
+		return err
 	}
+	pv.publishUnsafe(next, info)
+	return nil
+}
 
-	globalParams, urlRules, err := pv.parser.parseRulesUnsafe(rulesStr)
+// parseRulesUnsafe parses and compiles non-empty rules into snapshot before it is published
+func (rs *ruleSnapshot) parseRulesUnsafe(parser *RuleParser, rulesStr string) error {
+	parsed, err := parser.parseRuleSetUnsafe(rulesStr)
 	if err != nil {
 		return err
 	}
 
-	pv.globalParams = globalParams
-	pv.urlRules = urlRules
-	pv.rules = rulesStr
-	pv.compileRulesUnsafe()
+	rs.globalParams = parsed.globalParams
+	rs.urlRules = parsed.urlRules
+	rs.globalClauses = parsed.globalClauses
+	rs.rules = rulesStr
+	rs.compileRulesUnsafe()
 	return nil
 }
 
@@ -1044,92 +1323,149 @@ func (pv *ParamValidator) RulesString() (string, error) {
 	if !pv.initialized.Load() {
 		return "", fmt.Errorf("validator not initialized")
 	}
-	pv.mu.Lock()
-	defer pv.mu.Unlock()
-	result := pv.rules
-	return result, nil
+	return pv.current().rules, nil
 }
 
 // compileRulesUnsafe compiles rules for faster access with masks
-func (pv *ParamValidator) compileRulesUnsafe() {
-	if pv.paramIndex == nil {
-		pv.paramIndex = NewParamIndex()
+func (rs *ruleSnapshot) compileRulesUnsafe() {
+	if rs.paramIndex == nil {
+		rs.paramIndex = NewParamIndex()
 	} else {
-		pv.paramIndex.Clear()
+		rs.paramIndex.Clear()
 	}
 
-	pv.compiledRules = &CompiledRules{
+	rs.compiledRules = &CompiledRules{
 		globalParams:        make(map[string]*ParamRule),
 		urlRules:            make(map[string]*URLRule),
-		paramIndex:          pv.paramIndex,
+		paramIndex:          rs.paramIndex,
 		globalParamsByIndex: make(map[int]*ParamRule),
 		urlRulesByIndex:     make(map[int][]*URLRule),
 	}
 
 	// Copy global parameters and index them
-	for name, rule := range pv.globalParams {
-		ruleCopy := pv.copyParamRuleUnsafe(rule)
-		idx := pv.paramIndex.GetOrCreateIndex(name)
+	for name, rule := range rs.globalParams {
+		ruleCopy := rs.copyParamRuleUnsafe(rule)
+		idx := rs.paramIndex.GetOrCreateIndex(name)
 		if idx != -1 {
 			ruleCopy.BitmaskIndex = idx
-			pv.compiledRules.globalParams[name] = ruleCopy
-			pv.compiledRules.globalParamsByIndex[idx] = ruleCopy
+			rs.compiledRules.globalParams[name] = ruleCopy
+			rs.compiledRules.globalParamsByIndex[idx] = ruleCopy
+			rs.registerParamName(ruleCopy, idx)
+			if ruleCopy.Required || ruleCopy.MinOccurs > 0 {
+				rs.compiledRules.globalRequiredMask.SetBit(idx)
+				rs.compiledRules.hasRequired = true
+			}
+			if ruleCopy.MinOccurs > 0 || ruleCopy.MaxOccurs > 0 {
+				rs.compiledRules.hasOccurrences = true
+			}
+			if ruleCopy.DuplicatePolicy != DuplicateDefault {
+				rs.compiledRules.hasDuplicatePolicy = true
+			}
+			if ruleCopy.HasDefault {
+				rs.compiledRules.addDefaultParam(idx)
+			}
 		}
 	}
 
 	// Copy URL rules and create bit masks for them
-	for pattern, rule := range pv.urlRules {
+	for pattern, rule := range rs.urlRules {
 		ruleCopy := &URLRule{
 			URLPattern:    rule.URLPattern,
 			Params:        make(map[string]*ParamRule),
+			Clauses:       rs.copyRuleClauses(rule.Clauses),
 			ParamMask:     NewParamMask(),
 			paramsByIndex: make(map[int]*ParamRule),
 		}
 
 		for paramName, paramRule := range rule.Params {
-			paramRuleCopy := pv.copyParamRuleUnsafe(paramRule)
-			idx := pv.paramIndex.GetOrCreateIndex(paramName)
+			paramRuleCopy := rs.copyParamRuleUnsafe(paramRule)
+			idx := rs.paramIndex.GetOrCreateIndex(paramName)
 			if idx != -1 {
 				paramRuleCopy.BitmaskIndex = idx
 				ruleCopy.Params[paramName] = paramRuleCopy
 				ruleCopy.ParamMask.SetBit(idx)
 				ruleCopy.paramsByIndex[idx] = paramRuleCopy
+				rs.registerParamName(paramRuleCopy, idx)
+				if paramRuleCopy.Required || paramRuleCopy.MinOccurs > 0 {
+					ruleCopy.requiredMask.SetBit(idx)
+					rs.compiledRules.hasRequired = true
+				}
+				if paramRuleCopy.MinOccurs > 0 || paramRuleCopy.MaxOccurs > 0 {
+					rs.compiledRules.hasOccurrences = true
+				}
+				if paramRuleCopy.DuplicatePolicy != DuplicateDefault {
+					rs.compiledRules.hasDuplicatePolicy = true
+				}
+				if paramRuleCopy.HasDefault {
+					rs.compiledRules.addDefaultParam(idx)
+				}
+
+				rs.compiledRules.urlRulesByIndex[idx] = append(rs.compiledRules.urlRulesByIndex[idx], ruleCopy)
+			}
+		}
 
-				pv.compiledRules.urlRulesByIndex[idx] = append(pv.compiledRules.urlRulesByIndex[idx], ruleCopy)
+		rs.compileVariants(ruleCopy)
+		if !ruleCopy.Clauses.isEmpty() {
+			ruleCopy.clauses = rs.compileClauses(ruleCopy.Clauses, ruleCopy.Params)
+			rs.compiledRules.clauseRules = append(rs.compiledRules.clauseRules, ruleCopy)
+			rs.compiledRules.hasClauses = true
+			if len(ruleCopy.clauses.assertions) > 0 {
+				rs.compiledRules.hasAssertions = true
 			}
 		}
 
-		pv.compiledRules.urlRules[pattern] = ruleCopy
+		rs.compiledRules.urlRules[pattern] = ruleCopy
+	}
+
+	if !rs.globalClauses.isEmpty() {
+		rs.compiledRules.globalClauses = rs.compileClauses(rs.globalClauses, nil)
+		rs.compiledRules.hasClauses = true
+		if len(rs.compiledRules.globalClauses.assertions) > 0 {
+			rs.compiledRules.hasAssertions = true
+		}
+	}
+
+	if rs.duplicatePolicy != DuplicateDefault && rs.duplicatePolicy != DuplicateAllow {
+		rs.compiledRules.hasDuplicatePolicy = true
 	}
+	// Feature-free rules track presence only and skip zeroing per-parameter tracker state
+	rs.compiledRules.needsTrackerState = rs.compiledRules.hasOccurrences || rs.compiledRules.hasDuplicatePolicy ||
+		rs.compiledRules.hasClauses || rs.compiledRules.hasConditions
+	sortParamNamePatterns(rs.compiledRules.namePatterns)
+	rs.compiledRules.sortFoldedParamNames()
+	rs.compiledRules.sortDefaultParams()
 
 	// Pre-calculate global parameters mask
 	globalMask := NewParamMask()
-	for name := range pv.compiledRules.globalParams {
-		if idx := pv.paramIndex.GetIndex(name); idx != -1 {
+	for name := range rs.compiledRules.globalParams {
+		if idx := rs.paramIndex.GetIndex(name); idx != -1 {
 			globalMask.SetBit(idx)
 		}
 	}
-	pv.compiledRules.globalParamsMask = globalMask
+	rs.compiledRules.globalParamsMask = globalMask
 
-	pv.updateURLMatcherUnsafe()
+	rs.updateURLMatcherUnsafe()
 }
 
 // updateURLMatcherUnsafe updates URLMatcher with current rules
-func (pv *ParamValidator) updateURLMatcherUnsafe() {
-	if pv.urlMatcher == nil {
-		pv.urlMatcher = NewURLMatcher()
+func (rs *ruleSnapshot) updateURLMatcherUnsafe() {
+	if rs.urlMatcher == nil {
+		rs.urlMatcher = NewURLMatcher()
 	} else {
-		pv.urlMatcher.ClearRules()
+		rs.urlMatcher.ClearRules()
 	}
 
-	for pattern, rule := range pv.urlRules {
-		pv.urlMatcher.AddRule(pattern, rule)
+	for pattern, rule := range rs.compiledRules.urlRules {
+		rs.urlMatcher.AddRule(pattern, rule)
 	}
 }
 
 // isAllowAllParamsMasks checks if masks allow all parameters
-func (pv *ParamValidator) isAllowAllParamsMasks(masks ParamMasks) bool {
-	idx := pv.compiledRules.paramIndex.GetIndex(PatternAll)
+func (rs *ruleSnapshot) isAllowAllParamsMasks(masks ParamMasks) bool {
+	if rs.compiledRules == nil || rs.compiledRules.paramIndex == nil {
+		return false
+	}
+	idx := rs.compiledRules.paramIndex.GetIndex(PatternAll)
 	return idx != -1 && masks.CombinedMask().GetBit(idx)
 }
 
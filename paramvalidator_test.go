@@ -7,6 +7,21 @@ import (
 	"testing"
 )
 
+// newTestValidator creates validator with rules and options, failing test on error
+func newTestValidator(t testing.TB, rules string, options ...Option) *ParamValidator {
+	t.Helper()
+	pv, err := NewParamValidator(rules, options...)
+	if err != nil {
+		t.Fatalf("Failed to create validator: %v", err)
+	}
+	return pv
+}
+
+// usesBuffer checks that filtered result is slice of caller's buffer
+func usesBuffer(result, buffer []byte) bool {
+	return len(result) > 0 && &result[0] == &buffer[:1][0]
+}
+
 func TestNewParamValidator(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1607,3 +1622,140 @@ func TestCommentsInComplexRules(t *testing.T) {
 		})
 	}
 }
+
+func TestBytesZeroAllocs(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		options  []Option
+		urlPath  string
+		query    string
+		valid    bool
+		expected string
+	}{
+		{
+			name:     "occurrences",
+			rules:    "/search?tags=[a,b,c]{1,5}&page=[1,2]{0,1}",
+			urlPath:  "/search",
+			query:    "tags=a&tags=b&page=1",
+			valid:    true,
+			expected: "tags=a&tags=b&page=1",
+		},
+		{
+			name:     "duplicate policies with decoding",
+			rules:    "/search?sort=[name,date]@last&page=[1,2]@reject",
+			options:  []Option{WithDecoding(DecodeQuery)},
+			urlPath:  "/search",
+			query:    "sort=name&page=1&s%6Frt=date",
+			valid:    true,
+			expected: "page=1&s%6Frt=date",
+		},
+		{
+			name:     "dependency",
+			rules:    "/list?sort=[name]&order=[asc]&q=[*]; requires sort->order",
+			urlPath:  "/list",
+			query:    "sort=name&q=x",
+			valid:    false,
+			expected: "q=x",
+		},
+		{
+			name:     "groups",
+			rules:    "/search?q=[*]&sku=[*]&page=[*]; oneof(q,sku)",
+			urlPath:  "/search",
+			query:    "q=phone&page=2&sku=A",
+			valid:    false,
+			expected: "q=phone&page=2",
+		},
+		{
+			name:     "assertion",
+			rules:    "/products?min_price=[*]&max_price=[*]&page=[*]; assert(min_price <= max_price)",
+			urlPath:  "/products",
+			query:    "min_price=10.5&page=2&max_price=100",
+			valid:    true,
+			expected: "min_price=10.5&page=2&max_price=100",
+		},
+		{
+			name:     "condition",
+			rules:    "/catalog?category=[shoes,books]&subcategory=[*]; when(category=shoes) subcategory=[sneakers,boots]",
+			urlPath:  "/catalog",
+			query:    "subcategory=boots&category=shoes",
+			valid:    true,
+			expected: "subcategory=boots&category=shoes",
+		},
+		{
+			name:     "variants",
+			rules:    "/search?type=[image,video]&q=[*]; variant(type=image) width=[*]; variant(type=video) duration=[*]",
+			urlPath:  "/search",
+			query:    "width=800&q=cats&type=image",
+			valid:    true,
+			expected: "width=800&q=cats&type=image",
+		},
+		{
+			name:     "glob names",
+			rules:    "/track?utm_*=[*]&id=[1,2]",
+			urlPath:  "/track",
+			query:    "utm_source=a&utm_medium=b&id=1",
+			valid:    true,
+			expected: "utm_source=a&utm_medium=b&id=1",
+		},
+		{
+			name:     "decoding",
+			rules:    "/search?sort=[date_desc,date_asc]&q=[*]",
+			options:  []Option{WithDecoding(DecodeQuery)},
+			urlPath:  "/search",
+			query:    "sort=date%5Fdesc&q=hello+world",
+			valid:    true,
+			expected: "sort=date%5Fdesc&q=hello+world",
+		},
+		{
+			name:     "deprecated params",
+			rules:    "~old_filter=[*]; /list?page=[*]&~legacy=[a,b]&~mode=![debug]",
+			urlPath:  "/list",
+			query:    "legacy=a&page=1&old_filter=x",
+			valid:    true,
+			expected: "legacy=a&page=1&old_filter=x",
+		},
+		{
+			name:     "case folding with key rewriting",
+			rules:    "/list?page=[*]&sort=[name,date]:i&order=[asc,desc]",
+			options:  []Option{WithCaseFolding(CaseFoldKeys | CaseRewriteKeys), WithDecoding(DecodeQuery)},
+			urlPath:  "/list",
+			query:    "Page=2&SORT=Name&ORDER=asc",
+			valid:    true,
+			expected: "page=2&sort=Name&order=asc",
+		},
+		{
+			name:     "canonical order",
+			rules:    "/search?q=[*]&sort=[name,date]&page=[*]",
+			options:  []Option{WithCanonicalMode(CanonicalByRule), WithDecoding(DecodeQuery)},
+			urlPath:  "/search",
+			query:    "page=2&sort=date&q=red+shoes&q=red%20shoes",
+			valid:    true,
+			expected: "q=red%20shoes&sort=date&page=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, tt.rules, tt.options...)
+			urlPath := []byte(tt.urlPath)
+			query := []byte(tt.query)
+			// Canonical form and rewritten keys need room past filtered query
+			buffer := make([]byte, 0, 4*len(query))
+			scratch := make([]byte, 0, 64)
+
+			allocs := testing.AllocsPerRun(100, func() {
+				if pv.ValidateQueryBytesWithScratch(urlPath, query, scratch) != tt.valid {
+					t.Fatalf("Expected query validity %v", tt.valid)
+				}
+				pv.FilterQueryBytesWithScratch(urlPath, query, buffer, scratch)
+			})
+			if allocs != 0 {
+				t.Errorf("Expected zero allocations, got %v", allocs)
+			}
+			if result := pv.FilterQueryBytesWithScratch(urlPath, query, buffer, scratch); string(result) != tt.expected {
+				t.Errorf("FilterQueryBytesWithScratch = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
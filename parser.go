@@ -3,7 +3,9 @@ package paramvalidator
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 )
@@ -14,6 +16,12 @@ type PluginConstraintParser interface {
 	GetName() string
 }
 
+// PluginRepairParser is optional plugin capability supplying fix-ups for values rejected by constraint
+// ParseRepair returns nil function when constraint has no meaningful repair
+type PluginRepairParser interface {
+	ParseRepair(paramName, constraintStr string) (func(string) (string, bool), error)
+}
+
 // PluginResourceManager defines interface for plugin resource management
 type PluginResourceManager interface {
 	Close() error
@@ -124,8 +132,12 @@ func (rp *RuleParser) validateRulesString(rulesStr string) error {
 }
 
 // sanitizeParamName validates and cleans parameter name
+// Quoted names such as "filter[status]" are unquoted
 func (rp *RuleParser) sanitizeParamName(name string) (string, error) {
 	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		name = name[1 : len(name)-1]
+	}
 	if name == "" {
 		return "", fmt.Errorf("parameter name cannot be empty")
 	}
@@ -141,18 +153,75 @@ func (rp *RuleParser) sanitizeParamName(name string) (string, error) {
 }
 
 // isValidParamName checks if parameter name contains only allowed characters
+// Base name may be followed by bracket groups: ids[], filter[status] or wildcard filter[*]
+// '*' may be used anywhere as glob (utm_*), except as the whole name which means all parameters
 func (rp *RuleParser) isValidParamName(name string) bool {
-	for _, char := range name {
+	if name == PatternAll {
+		return false
+	}
+
+	base := strings.IndexByte(name, '[')
+	if base == -1 {
+		return isParamNameChars(name)
+	}
+	if base == 0 || !isParamNameChars(name[:base]) {
+		return false
+	}
+
+	for rest := name[base:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end == -1 || !isParamNameChars(rest[1:end]) {
+			return false
+		}
+		rest = rest[end+1:]
+	}
+	return true
+}
+
+// isParamNameChars checks that string contains only letters, digits, '-', '_' and '*' glob
+func isParamNameChars(str string) bool {
+	for _, char := range str {
 		if !((char >= 'a' && char <= 'z') ||
 			(char >= 'A' && char <= 'Z') ||
 			(char >= '0' && char <= '9') ||
-			char == '-' || char == '_') {
+			char == '-' || char == '_' || char == '*') {
 			return false
 		}
 	}
 	return true
 }
 
+// paramNameEnd returns position where parameter name ends in rule string
+// Bracket groups belong to the name only when followed by '=', so ids[]=[*] names "ids[]"
+// while value[*] keeps its constraint meaning
+func (rp *RuleParser) paramNameEnd(ruleStr string) int {
+	if strings.HasPrefix(ruleStr, `"`) {
+		if end := strings.IndexByte(ruleStr[1:], '"'); end != -1 {
+			return end + 2
+		}
+		return len(ruleStr)
+	}
+
+	nameEnd := 0
+	for nameEnd < len(ruleStr) && ruleStr[nameEnd] != '[' && ruleStr[nameEnd] != '=' {
+		nameEnd++
+	}
+
+	i := nameEnd
+	for i < len(ruleStr) && ruleStr[i] == '[' {
+		end := strings.IndexByte(ruleStr[i:], ']')
+		if end == -1 {
+			break
+		}
+		i += end + 1
+	}
+
+	if i > nameEnd && i < len(ruleStr) && ruleStr[i] == '=' {
+		return i
+	}
+	return nameEnd
+}
+
 // isValidURLPattern validates URL pattern for security and format
 func (rp *RuleParser) isValidURLPattern(pattern string) bool {
 	if pattern == "" || len(pattern) > MaxURLLength {
@@ -161,49 +230,75 @@ func (rp *RuleParser) isValidURLPattern(pattern string) bool {
 	return true
 }
 
+// parsedRules holds parameter rules and clauses parsed from rules string
+type parsedRules struct {
+	globalParams  map[string]*ParamRule
+	urlRules      map[string]*URLRule
+	globalClauses RuleClauses
+}
+
 // parseRulesUnsafe parses rules string without locking
 func (rp *RuleParser) parseRulesUnsafe(rulesStr string) (map[string]*ParamRule, map[string]*URLRule, error) {
+	parsed, err := rp.parseRuleSetUnsafe(rulesStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parsed.globalParams, parsed.urlRules, nil
+}
+
+// parseRuleSetUnsafe parses rules string including clauses without locking
+func (rp *RuleParser) parseRuleSetUnsafe(rulesStr string) (*parsedRules, error) {
+	parsed := &parsedRules{
+		globalParams: make(map[string]*ParamRule),
+		urlRules:     make(map[string]*URLRule),
+	}
+
 	if rulesStr == "" {
-		return make(map[string]*ParamRule), make(map[string]*URLRule), nil
+		return parsed, nil
 	}
 
 	// Remove comments before parsing
 	rulesStr = rp.removeComments(rulesStr)
 
 	if err := rp.validateRulesString(rulesStr); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	globalParams := make(map[string]*ParamRule)
-	urlRules := make(map[string]*URLRule)
-
 	ruleType := rp.detectRuleType(rulesStr)
 
 	switch ruleType {
 	case RuleTypeURL:
-		parsedURLRules, parsedGlobalParams, err := rp.parseURLRulesUnsafe(rulesStr)
+		parsedURLRules, parsedGlobalParams, err := rp.parseURLRulesUnsafe(rulesStr, &parsed.globalClauses)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		for k, v := range parsedURLRules {
-			urlRules[k] = v
+			parsed.urlRules[k] = v
 		}
 		for k, v := range parsedGlobalParams {
-			globalParams[k] = v
+			parsed.globalParams[k] = v
 		}
 	case RuleTypeGlobal:
-		parsedGlobalParams, err := rp.parseGlobalParamsUnsafe(rulesStr)
+		parsedGlobalParams, err := rp.parseGlobalParamsUnsafe(rulesStr, &parsed.globalClauses)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		for k, v := range parsedGlobalParams {
-			globalParams[k] = v
+			parsed.globalParams[k] = v
 		}
 	default:
-		return nil, nil, fmt.Errorf("unknown rule type")
+		return nil, fmt.Errorf("unknown rule type")
+	}
+
+	if err := rp.validateClauseParams(parsed); err != nil {
+		return nil, err
 	}
 
-	return globalParams, urlRules, nil
+	if err := rp.validateParamAliases(parsed); err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
 }
 
 // detectRuleType determines the type of rules in the string
@@ -232,53 +327,82 @@ func (rp *RuleParser) detectRuleType(rulesStr string) RuleType {
 }
 
 // parseGlobalParamsUnsafe parses global parameter rules with separator support
-func (rp *RuleParser) parseGlobalParamsUnsafe(rulesStr string) (map[string]*ParamRule, error) {
+// Clause statements are collected into clauses
+func (rp *RuleParser) parseGlobalParamsUnsafe(rulesStr string, clauses *RuleClauses) (map[string]*ParamRule, error) {
 	// Remove comments first
 	rulesStr = rp.removeComments(rulesStr)
 
 	ruleStrings := rp.splitRulesMulti(rulesStr, []byte{';', '\n'})
 
 	params := make(map[string]*ParamRule)
+	declared := 0
 
 	for _, ruleStr := range ruleStrings {
 		if ruleStr == "" {
 			continue
 		}
 
+		if isClauseStatement(ruleStr) {
+			if err := rp.parseClauseStatement(ruleStr, clauses); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		ruleParams, err := rp.parseParamsFromString(ruleStr, '&')
 		if err != nil {
 			return nil, err
 		}
 
 		for k, v := range ruleParams {
+			v.position += declared
 			params[k] = v
 		}
+		// Statement length bounds its parameter count, keeping positions ordered across statements
+		declared += len(ruleStr)
 	}
 
 	return params, nil
 }
 
 // parseURLRulesUnsafe parses URL-specific rules
-func (rp *RuleParser) parseURLRulesUnsafe(rulesStr string) (map[string]*URLRule, map[string]*ParamRule, error) {
+// Clause statements attach to the preceding URL rule, or to globalClauses after global params
+func (rp *RuleParser) parseURLRulesUnsafe(rulesStr string, globalClauses *RuleClauses) (map[string]*URLRule, map[string]*ParamRule, error) {
 	// Remove comments first
 	rulesStr = rp.removeComments(rulesStr)
 
 	urlRules := make(map[string]*URLRule)
 	globalParams := make(map[string]*ParamRule)
+	clauseTarget := globalClauses
+	declared := 0
 
-	urlRuleStrings := rp.splitURLRules(rulesStr)
+	urlRuleStrings, err := rp.splitURLRules(rulesStr)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	for _, urlRuleStr := range urlRuleStrings {
 		if urlRuleStr == "" {
 			continue
 		}
+		offset := declared
+		declared += len(urlRuleStr)
+
+		if isClauseStatement(urlRuleStr) {
+			if err := rp.parseClauseStatement(urlRuleStr, clauseTarget); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
 
 		if rp.detectRuleType(urlRuleStr) == RuleTypeGlobal {
-			parsedGlobalParams, err := rp.parseGlobalParamsUnsafe(urlRuleStr)
+			clauseTarget = globalClauses
+			parsedGlobalParams, err := rp.parseGlobalParamsUnsafe(urlRuleStr, globalClauses)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to parse global params: %w", err)
 			}
 			for k, v := range parsedGlobalParams {
+				v.position += offset
 				globalParams[k] = v
 			}
 			continue
@@ -287,11 +411,13 @@ func (rp *RuleParser) parseURLRulesUnsafe(rulesStr string) (map[string]*URLRule,
 		urlPattern, paramsStr := rp.extractURLAndParams(urlRuleStr)
 
 		if urlPattern == "" && paramsStr != "" {
-			parsedGlobalParams, err := rp.parseGlobalParamsUnsafe(paramsStr)
+			clauseTarget = globalClauses
+			parsedGlobalParams, err := rp.parseGlobalParamsUnsafe(paramsStr, globalClauses)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to parse global params: %w", err)
 			}
 			for k, v := range parsedGlobalParams {
+				v.position += offset
 				globalParams[k] = v
 			}
 			continue
@@ -308,6 +434,7 @@ func (rp *RuleParser) parseURLRulesUnsafe(rulesStr string) (map[string]*URLRule,
 				Params:     params,
 			}
 			urlRules[urlPattern] = urlRule
+			clauseTarget = &urlRule.Clauses
 		}
 	}
 
@@ -332,12 +459,13 @@ func (rp *RuleParser) parseParamsFromString(paramsStr string, separator byte) (m
 
 	paramStrings := rp.splitRules(paramsStr, separator)
 
-	for _, paramStr := range paramStrings {
+	for i, paramStr := range paramStrings {
 		rule, err := rp.parseSingleParamRuleUnsafe(paramStr)
 		if err != nil {
 			return nil, err
 		}
 		if rule != nil {
+			rule.position = i
 			params[rule.Name] = rule
 		}
 	}
@@ -388,22 +516,23 @@ func (rp *RuleParser) splitRules(rulesStr string, separator byte) []string {
 }
 
 // splitURLRules splits URL rules string by semicolon or newline
-func (rp *RuleParser) splitURLRules(rulesStr string) []string {
+func (rp *RuleParser) splitURLRules(rulesStr string) ([]string, error) {
 	if rp.detectRuleType(rulesStr) == RuleTypeGlobal {
-		return []string{rulesStr}
+		return []string{rulesStr}, nil
 	}
 
-	var builder strings.Builder
-	builder.Grow(len(rulesStr))
-
-	for _, r := range rulesStr {
-		if r != ' ' {
-			builder.WriteRune(r)
+	// Spaces are insignificant in URL rules but separate words in clause statements
+	ruleStrings := rp.splitRulesMulti(rulesStr, []byte{';', '\n'})
+	for i, ruleStr := range ruleStrings {
+		if !isClauseStatement(ruleStr) {
+			if err := checkDefaultSpaces(ruleStr); err != nil {
+				return nil, err
+			}
+			ruleStrings[i] = strings.ReplaceAll(ruleStr, " ", "")
 		}
 	}
-	cleanRulesStr := builder.String()
 
-	return rp.splitRulesMulti(cleanRulesStr, []byte{';', '\n'})
+	return ruleStrings, nil
 }
 
 // splitRulesMulti splits rules string considering multiple separators and bracket nesting
@@ -507,12 +636,649 @@ func (rp *RuleParser) extractURLAndParams(urlRuleStr string) (string, string) {
 	return "", urlRuleStr
 }
 
-// parseSingleParamRuleUnsafe parses single parameter rule
+// isClauseStatement checks if rule statement is a clause such as "requires sort->order"
+func isClauseStatement(ruleStr string) bool {
+	keyword, _ := splitClauseKeyword(ruleStr)
+	return keyword != ""
+}
+
+// splitClauseKeyword separates known clause keyword from its arguments
+func splitClauseKeyword(ruleStr string) (string, string) {
+	end := 0
+	for end < len(ruleStr) && ruleStr[end] >= 'a' && ruleStr[end] <= 'z' {
+		end++
+	}
+	if end == len(ruleStr) || (ruleStr[end] != ' ' && ruleStr[end] != '\t' && ruleStr[end] != '(') {
+		return "", ruleStr
+	}
+
+	for _, keyword := range clauseKeywords {
+		if ruleStr[:end] == keyword {
+			return keyword, strings.TrimSpace(ruleStr[end:])
+		}
+	}
+	return "", ruleStr
+}
+
+// parseClauseStatement parses clause statement and appends it to clauses
+func (rp *RuleParser) parseClauseStatement(ruleStr string, clauses *RuleClauses) error {
+	keyword, args := splitClauseKeyword(ruleStr)
+
+	switch keyword {
+	case "requires":
+		dependency, err := rp.parseDependency(args)
+		if err != nil {
+			return err
+		}
+		clauses.Dependencies = append(clauses.Dependencies, dependency)
+	case "oneof", "exclusive", "atleastone":
+		group, err := rp.parseParamGroup(groupKinds[keyword], args)
+		if err != nil {
+			return err
+		}
+		clauses.Groups = append(clauses.Groups, group)
+	case "assert":
+		assertion, err := rp.parseAssertion(args)
+		if err != nil {
+			return err
+		}
+		clauses.Assertions = append(clauses.Assertions, assertion)
+	case "when":
+		condition, err := rp.parseCondition(args)
+		if err != nil {
+			return err
+		}
+		clauses.Conditions = append(clauses.Conditions, condition)
+	case "variant":
+		variant, err := rp.parseDiscriminatedRules(keyword, args)
+		if err != nil {
+			return err
+		}
+		clauses.Variants = append(clauses.Variants, variant)
+	default:
+		return fmt.Errorf("unknown clause: %s", ruleStr)
+	}
+
+	return nil
+}
+
+// parseDependency parses dependency in form param->prerequisite[,prerequisite...]
+func (rp *RuleParser) parseDependency(args string) (Dependency, error) {
+	paramStr, requiresStr, ok := strings.Cut(args, "->")
+	if !ok {
+		return Dependency{}, fmt.Errorf("invalid requires clause, expected 'param->prerequisite': %s", args)
+	}
+
+	param, err := rp.sanitizeParamName(paramStr)
+	if err != nil {
+		return Dependency{}, fmt.Errorf("invalid parameter name in requires clause: %w", err)
+	}
+
+	requires, err := rp.parseClauseNames(requiresStr)
+	if err != nil {
+		return Dependency{}, fmt.Errorf("invalid prerequisite in requires clause: %w", err)
+	}
+
+	for _, name := range requires {
+		if name == param {
+			return Dependency{}, fmt.Errorf("parameter '%s' cannot require itself", param)
+		}
+	}
+
+	return Dependency{Param: param, Requires: requires}, nil
+}
+
+// groupKinds maps group clause keywords to group kinds
+var groupKinds = map[string]GroupKind{
+	"oneof":      GroupOneOf,
+	"exclusive":  GroupExclusive,
+	"atleastone": GroupAtLeastOne,
+}
+
+// parseParamGroup parses group in form (alternative | alternative...)
+// Without '|' every comma separated name is an alternative of its own
+func (rp *RuleParser) parseParamGroup(kind GroupKind, args string) (ParamGroup, error) {
+	if len(args) < 2 || args[0] != '(' || args[len(args)-1] != ')' {
+		return ParamGroup{}, fmt.Errorf("invalid %s clause, expected parenthesized parameter list: %s", kind, args)
+	}
+	args = args[1 : len(args)-1]
+
+	group := ParamGroup{Kind: kind}
+	seen := make(map[string]bool)
+	alternativeStrs := strings.Split(args, "|")
+	if len(alternativeStrs) == 1 {
+		alternativeStrs = strings.Split(args, ",")
+	}
+
+	for _, alternativeStr := range alternativeStrs {
+		alternative, err := rp.parseClauseNames(alternativeStr)
+		if err != nil {
+			return ParamGroup{}, fmt.Errorf("invalid parameter in %s clause: %w", kind, err)
+		}
+		for _, name := range alternative {
+			if seen[name] {
+				return ParamGroup{}, fmt.Errorf("parameter '%s' listed more than once in %s clause", name, kind)
+			}
+			seen[name] = true
+		}
+		group.Alternatives = append(group.Alternatives, alternative)
+	}
+
+	if len(group.Alternatives) < 2 {
+		return ParamGroup{}, fmt.Errorf("%s clause needs at least two alternatives: %s", kind, args)
+	}
+
+	return group, nil
+}
+
+// parseAssertion parses assertion in form (left operator right)
+func (rp *RuleParser) parseAssertion(args string) (Assertion, error) {
+	if len(args) < 2 || args[0] != '(' || args[len(args)-1] != ')' {
+		return Assertion{}, fmt.Errorf("invalid assert clause, expected parenthesized comparison: %s", args)
+	}
+	expr := args[1 : len(args)-1]
+
+	for i := 0; i < len(expr); i++ {
+		for _, operator := range assertOperators {
+			if !strings.HasPrefix(expr[i:], operator) {
+				continue
+			}
+
+			left, err := rp.sanitizeParamName(expr[:i])
+			if err != nil {
+				return Assertion{}, fmt.Errorf("invalid left operand in assert clause: %w", err)
+			}
+			right, err := rp.sanitizeParamName(expr[i+len(operator):])
+			if err != nil {
+				return Assertion{}, fmt.Errorf("invalid right operand in assert clause: %w", err)
+			}
+			if left == right {
+				return Assertion{}, fmt.Errorf("parameter '%s' cannot be compared with itself", left)
+			}
+
+			return Assertion{Left: left, Operator: operator, Right: right}, nil
+		}
+	}
+
+	return Assertion{}, fmt.Errorf("invalid assert clause, expected one of %s: %s", strings.Join(assertOperators, " "), args)
+}
+
+// parseCondition parses when clause, conditional rules narrow values only
+// so modifiers and suffix options are rejected
+func (rp *RuleParser) parseCondition(args string) (Condition, error) {
+	condition, err := rp.parseDiscriminatedRules("when", args)
+	if err != nil {
+		return Condition{}, err
+	}
+
+	for name, rule := range condition.Params {
+		if rule.Required || rule.MinOccurs != 0 || rule.MaxOccurs != 0 || rule.DuplicatePolicy != DuplicateDefault || rule.HasDefault {
+			return Condition{}, fmt.Errorf("when clause supports value constraints only, got options for '%s'", name)
+		}
+	}
+
+	return condition, nil
+}
+
+// parseDiscriminatedRules parses clause arguments in form (param=value[,value...]) param=[constraint][&param=[constraint]...]
+func (rp *RuleParser) parseDiscriminatedRules(keyword, args string) (Condition, error) {
+	end := strings.IndexByte(args, ')')
+	if len(args) == 0 || args[0] != '(' || end == -1 {
+		return Condition{}, fmt.Errorf("invalid %s clause, expected '(param=value) rules': %s", keyword, args)
+	}
+
+	paramStr, valuesStr, ok := strings.Cut(args[1:end], "=")
+	if !ok {
+		return Condition{}, fmt.Errorf("invalid %s clause, expected 'param=value' discriminator: %s", keyword, args)
+	}
+
+	param, err := rp.sanitizeParamName(paramStr)
+	if err != nil {
+		return Condition{}, fmt.Errorf("invalid discriminator in %s clause: %w", keyword, err)
+	}
+
+	condition := Condition{Param: param}
+	for _, value := range strings.Split(valuesStr, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return Condition{}, fmt.Errorf("empty discriminator value in %s clause: %s", keyword, args)
+		}
+		condition.Values = append(condition.Values, value)
+	}
+
+	condition.Params, err = rp.parseParamsFromString(strings.TrimSpace(args[end+1:]), '&')
+	if err != nil {
+		return Condition{}, fmt.Errorf("invalid rule in %s clause: %w", keyword, err)
+	}
+	if len(condition.Params) == 0 {
+		return Condition{}, fmt.Errorf("%s clause has no parameter rules: %s", keyword, args)
+	}
+
+	for name := range condition.Params {
+		if name == param || name == PatternAll {
+			return Condition{}, fmt.Errorf("%s clause cannot declare parameter '%s'", keyword, name)
+		}
+	}
+
+	return condition, nil
+}
+
+// parseClauseNames parses comma separated parameter names, skipping repeats
+func (rp *RuleParser) parseClauseNames(namesStr string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, nameStr := range strings.Split(namesStr, ",") {
+		name, err := rp.sanitizeParamName(nameStr)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// validateClauseParams checks that clauses reference declared parameters
+// URL rule clauses may reference their own parameters and globals, global clauses only globals
+func (rp *RuleParser) validateClauseParams(parsed *parsedRules) error {
+	for _, name := range parsed.globalClauses.paramNames() {
+		if _, exists := parsed.globalParams[name]; !exists {
+			return fmt.Errorf("clause references undeclared parameter '%s'", name)
+		}
+	}
+
+	if len(parsed.globalClauses.Variants) > 0 {
+		return fmt.Errorf("variant clause must follow URL rule")
+	}
+
+	for pattern, urlRule := range parsed.urlRules {
+		for _, name := range urlRule.Clauses.paramNames() {
+			_, isGlobal := parsed.globalParams[name]
+			if _, exists := urlRule.Params[name]; !exists && !isGlobal {
+				return fmt.Errorf("clause for URL %s references undeclared parameter '%s'", pattern, name)
+			}
+		}
+		for _, variant := range urlRule.Clauses.Variants {
+			if _, exists := urlRule.Params[variant.Param]; !exists {
+				return fmt.Errorf("variant for URL %s uses undeclared discriminator '%s'", pattern, variant.Param)
+			}
+			for name := range variant.Params {
+				if _, exists := urlRule.Params[name]; exists {
+					return fmt.Errorf("variant for URL %s redeclares parameter '%s'", pattern, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateParamAliases checks that every alias stands for one primary name across whole rule set
+// Alias shares parameter index with its primary name, so it cannot be declared as parameter itself
+func (rp *RuleParser) validateParamAliases(parsed *parsedRules) error {
+	var rules []*ParamRule
+	collect := func(params map[string]*ParamRule) {
+		for _, rule := range params {
+			rules = append(rules, rule)
+		}
+	}
+	collectClauses := func(clauses RuleClauses) error {
+		for _, condition := range clauses.Conditions {
+			for name, rule := range condition.Params {
+				if len(rule.Aliases) > 0 {
+					return fmt.Errorf("condition rule of parameter '%s' cannot declare aliases", name)
+				}
+			}
+		}
+		for _, variant := range clauses.Variants {
+			collect(variant.Params)
+		}
+		return nil
+	}
+
+	collect(parsed.globalParams)
+	if err := collectClauses(parsed.globalClauses); err != nil {
+		return err
+	}
+	for _, urlRule := range parsed.urlRules {
+		collect(urlRule.Params)
+		if err := collectClauses(urlRule.Clauses); err != nil {
+			return err
+		}
+	}
+
+	primaries := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		primaries[rule.Name] = true
+	}
+
+	aliasOf := make(map[string]string)
+	for _, rule := range rules {
+		for _, alias := range rule.Aliases {
+			if primaries[alias] {
+				return fmt.Errorf("alias '%s' of parameter '%s' is declared as parameter", alias, rule.Name)
+			}
+			if primary, exists := aliasOf[alias]; exists && primary != rule.Name {
+				return fmt.Errorf("alias '%s' is used by parameters '%s' and '%s'", alias, primary, rule.Name)
+			}
+			aliasOf[alias] = rule.Name
+		}
+	}
+	return nil
+}
+
+// paramModifiers contains markers placed before parameter name
+type paramModifiers struct {
+	required   bool
+	deprecated bool
+}
+
+// parseSingleParamRuleUnsafe parses single parameter rule with modifiers
 func (rp *RuleParser) parseSingleParamRuleUnsafe(ruleStr string) (*ParamRule, error) {
 	ruleStr = strings.TrimSpace(ruleStr)
 	if ruleStr == "" {
 		return nil, nil
 	}
+
+	modifiers, ruleStr, err := rp.extractParamModifiers(ruleStr)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleStr, aliases, err := rp.extractParamAliases(ruleStr)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleStr, suffix := rp.extractRuleSuffix(ruleStr)
+
+	rule, err := rp.parseParamRuleBody(ruleStr)
+	if err != nil || rule == nil {
+		return rule, err
+	}
+
+	if err := rp.parseRuleSuffix(rule, suffix); err != nil {
+		return nil, err
+	}
+
+	if err := rp.applyParamModifiers(rule, modifiers); err != nil {
+		return nil, err
+	}
+
+	if err := rp.applyParamAliases(rule, aliases); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// extractParamAliases separates aliases listed after primary name, e.g. q in "query|q=[*]"
+func (rp *RuleParser) extractParamAliases(ruleStr string) (string, []string, error) {
+	bracketDepth := 0
+	inQuotes := false
+	nameEnd := len(ruleStr)
+	var separators []int
+
+	for i := 0; i < len(ruleStr) && nameEnd == len(ruleStr); i++ {
+		if ruleStr[i] == '"' {
+			inQuotes = !inQuotes
+		}
+		if inQuotes {
+			continue
+		}
+
+		switch ruleStr[i] {
+		case '[':
+			bracketDepth++
+		case ']':
+			if bracketDepth > 0 {
+				bracketDepth--
+			}
+		case '|':
+			if bracketDepth == 0 {
+				separators = append(separators, i)
+			}
+		case '=', '!':
+			if bracketDepth == 0 {
+				nameEnd = i
+			}
+		}
+	}
+
+	if len(separators) == 0 {
+		return ruleStr, nil, nil
+	}
+
+	aliases := make([]string, 0, len(separators))
+	for i, separator := range separators {
+		end := nameEnd
+		if i+1 < len(separators) {
+			end = separators[i+1]
+		}
+		alias, err := rp.sanitizeParamName(ruleStr[separator+1 : end])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid parameter alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	return ruleStr[:separators[0]] + ruleStr[nameEnd:], aliases, nil
+}
+
+// applyParamAliases attaches aliases to parsed rule
+// Aliases must be plain names distinct from each other and from primary name
+func (rp *RuleParser) applyParamAliases(rule *ParamRule, aliases []string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	if isParamNamePattern(rule.Name) {
+		return fmt.Errorf("pattern parameter '%s' cannot have aliases", rule.Name)
+	}
+
+	for i, alias := range aliases {
+		if isParamNamePattern(alias) {
+			return fmt.Errorf("alias '%s' of parameter '%s' cannot be pattern", alias, rule.Name)
+		}
+		if alias == rule.Name || slices.Contains(aliases[:i], alias) {
+			return fmt.Errorf("duplicate alias '%s' of parameter '%s'", alias, rule.Name)
+		}
+	}
+	rule.Aliases = aliases
+	return nil
+}
+
+// extractRuleSuffix separates options placed after the closing constraint bracket
+func (rp *RuleParser) extractRuleSuffix(ruleStr string) (string, string) {
+	bracketDepth := 0
+	lastClose := -1
+	inQuotes := false
+
+	for i := 0; i < len(ruleStr); i++ {
+		if ruleStr[i] == '"' && bracketDepth == 0 {
+			inQuotes = !inQuotes
+		}
+		if inQuotes {
+			continue
+		}
+
+		switch ruleStr[i] {
+		case '[':
+			bracketDepth++
+		case ']':
+			if bracketDepth > 0 {
+				bracketDepth--
+				if bracketDepth == 0 {
+					lastClose = i
+				}
+			}
+		}
+	}
+
+	if lastClose == -1 || lastClose == len(ruleStr)-1 || bracketDepth != 0 {
+		return ruleStr, ""
+	}
+	return ruleStr[:lastClose+1], strings.TrimSpace(ruleStr[lastClose+1:])
+}
+
+// parseRuleSuffix applies options placed after the constraint, e.g. occurrence count {1,5} or duplicate policy @first
+func (rp *RuleParser) parseRuleSuffix(rule *ParamRule, suffix string) error {
+	hasOccurrences := false
+
+	for suffix != "" {
+		switch suffix[0] {
+		case '{':
+			end := strings.IndexByte(suffix, '}')
+			if end == -1 {
+				return fmt.Errorf("unclosed occurrence count for parameter '%s': %s", rule.Name, suffix)
+			}
+			if hasOccurrences {
+				return fmt.Errorf("duplicate occurrence count for parameter '%s'", rule.Name)
+			}
+			if err := rp.parseOccurrences(rule, suffix[1:end]); err != nil {
+				return err
+			}
+			hasOccurrences = true
+			suffix = strings.TrimSpace(suffix[end+1:])
+		case '@':
+			end := 1
+			for end < len(suffix) && suffix[end] >= 'a' && suffix[end] <= 'z' {
+				end++
+			}
+			policy, ok := parseDuplicatePolicy(suffix[1:end])
+			if !ok {
+				return fmt.Errorf("unknown duplicate policy for parameter '%s': %s", rule.Name, suffix[:end])
+			}
+			if rule.DuplicatePolicy != DuplicateDefault {
+				return fmt.Errorf("duplicate policy specified twice for parameter '%s'", rule.Name)
+			}
+			rule.DuplicatePolicy = policy
+			suffix = strings.TrimSpace(suffix[end:])
+		case ':':
+			if len(suffix) < 2 || suffix[1] != 'i' {
+				return fmt.Errorf("unknown flag for parameter '%s': %s", rule.Name, suffix)
+			}
+			if rule.CaseInsensitive {
+				return fmt.Errorf("case-insensitive flag specified twice for parameter '%s'", rule.Name)
+			}
+			rule.CaseInsensitive = true
+			suffix = strings.TrimSpace(suffix[2:])
+		case '=':
+			// Default value takes the rest of suffix, so it must come last
+			rule.Default = strings.TrimSpace(suffix[1:])
+			if rule.Default == "" {
+				return fmt.Errorf("empty default value for parameter '%s'", rule.Name)
+			}
+			rule.HasDefault = true
+			if err := checkDefaultValue(rule); err != nil {
+				return err
+			}
+			suffix = ""
+		default:
+			return fmt.Errorf("unexpected characters after constraint for parameter '%s': %s", rule.Name, suffix)
+		}
+	}
+
+	// Occurrence counts permitting repeats contradict policies that forbid or collapse them
+	if hasOccurrences && rule.MaxOccurs != 1 &&
+		rule.DuplicatePolicy != DuplicateDefault && rule.DuplicatePolicy != DuplicateAllow {
+		return fmt.Errorf("duplicate policy @%s conflicts with occurrence count for parameter '%s'", rule.DuplicatePolicy, rule.Name)
+	}
+
+	return nil
+}
+
+// parseOccurrences parses occurrence count in forms n, n,m, n, and ,m
+func (rp *RuleParser) parseOccurrences(rule *ParamRule, countStr string) error {
+	minStr, maxStr, hasComma := strings.Cut(countStr, ",")
+	minStr = strings.TrimSpace(minStr)
+	maxStr = strings.TrimSpace(maxStr)
+
+	if minStr == "" && maxStr == "" {
+		return fmt.Errorf("empty occurrence count for parameter '%s'", rule.Name)
+	}
+
+	parseCount := func(str string) (int, error) {
+		if str == "" {
+			return 0, nil
+		}
+		count, err := strconv.Atoi(str)
+		if err != nil || count < 0 || count > MaxParamValues {
+			return 0, fmt.Errorf("invalid occurrence count for parameter '%s': {%s}", rule.Name, countStr)
+		}
+		return count, nil
+	}
+
+	minCount, err := parseCount(minStr)
+	if err != nil {
+		return err
+	}
+
+	maxCount := minCount
+	if hasComma {
+		if maxCount, err = parseCount(maxStr); err != nil {
+			return err
+		}
+	}
+
+	// Empty maximum after comma means unlimited, explicit maximum must be positive
+	if (!hasComma || maxStr != "") && maxCount == 0 {
+		return fmt.Errorf("maximum occurrence count must be positive for parameter '%s'", rule.Name)
+	}
+	if maxCount != 0 && minCount > maxCount {
+		return fmt.Errorf("invalid occurrence count for parameter '%s': min %d > max %d", rule.Name, minCount, maxCount)
+	}
+
+	rule.MinOccurs = minCount
+	rule.MaxOccurs = maxCount
+	return nil
+}
+
+// extractParamModifiers strips modifier markers from the start of rule string
+func (rp *RuleParser) extractParamModifiers(ruleStr string) (paramModifiers, string, error) {
+	var modifiers paramModifiers
+
+	for len(ruleStr) > 0 {
+		switch ruleStr[0] {
+		case '+':
+			if modifiers.required {
+				return modifiers, "", fmt.Errorf("duplicate required marker in rule: %s", ruleStr)
+			}
+			modifiers.required = true
+		case '~':
+			if modifiers.deprecated {
+				return modifiers, "", fmt.Errorf("duplicate deprecation marker in rule: %s", ruleStr)
+			}
+			modifiers.deprecated = true
+		default:
+			return modifiers, ruleStr, nil
+		}
+		ruleStr = strings.TrimSpace(ruleStr[1:])
+	}
+
+	return modifiers, "", fmt.Errorf("missing parameter after modifiers")
+}
+
+// applyParamModifiers applies modifiers to parsed rule and rejects contradictory combinations
+func (rp *RuleParser) applyParamModifiers(rule *ParamRule, modifiers paramModifiers) error {
+	if modifiers.required {
+		if rule.Inverted {
+			return fmt.Errorf("parameter '%s' cannot be both required and inverted", rule.Name)
+		}
+		rule.Required = true
+	}
+	if modifiers.deprecated {
+		if rule.Required {
+			return fmt.Errorf("parameter '%s' cannot be both required and deprecated", rule.Name)
+		}
+		rule.Deprecated = true
+	}
+	return nil
+}
+
+// parseParamRuleBody parses parameter rule without modifiers
+func (rp *RuleParser) parseParamRuleBody(ruleStr string) (*ParamRule, error) {
 	ruleStr = strings.ReplaceAll(ruleStr, "**", "*")
 	ruleStr = strings.ReplaceAll(ruleStr, "![*]", "[]")
 
@@ -548,10 +1314,12 @@ func (rp *RuleParser) parseSingleParamRuleUnsafe(ruleStr string) (*ParamRule, er
 		}, nil
 	}
 
-	startBracket := strings.Index(ruleStr, "[")
+	nameEnd := rp.paramNameEnd(ruleStr)
+	startBracket := strings.IndexByte(ruleStr[nameEnd:], '[')
 	if startBracket == -1 {
 		return rp.parseSimpleParamRule(ruleStr)
 	}
+	startBracket += nameEnd
 
 	return rp.parseComplexParamRule(ruleStr, startBracket, inverted)
 }
@@ -628,6 +1396,9 @@ func (rp *RuleParser) parseComplexParamRule(ruleStr string, startBracket int, in
 	}
 
 	rule.Inverted = inverted
+	if inverted && len(rule.Mappings) > 0 {
+		return nil, fmt.Errorf("inverted parameter '%s' cannot map values", paramName)
+	}
 	return rule, nil
 }
 
@@ -661,8 +1432,49 @@ func (rp *RuleParser) extractConstraint(ruleStr string, startBracket int) (strin
 	return constraint, endBracket
 }
 
-// createParamRule creates parameter rule using plugins or standard parsing
+// createParamRule creates parameter rule with leading transform steps of constraint
 func (rp *RuleParser) createParamRule(paramName, constraintStr string) (*ParamRule, error) {
+	transforms, constraintStr := parseTransforms(constraintStr)
+	if len(transforms) > 0 && constraintStr == "" {
+		return nil, fmt.Errorf("transforms of parameter '%s' require value constraint", paramName)
+	}
+
+	mappings, constraintStr, err := parseValueMappings(constraintStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value mapping for parameter '%s': %w", paramName, err)
+	}
+
+	rule, err := rp.createConstraintRule(paramName, constraintStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transforms) > 0 {
+		rule.Transforms = transforms
+		// Enum values are compared with transformed values, so they are transformed as well
+		if rule.Pattern == PatternEnum {
+			for i, value := range rule.Values {
+				rule.Values[i] = rule.transformValue(value)
+			}
+			sort.Strings(rule.Values)
+		}
+	}
+
+	if len(mappings) > 0 {
+		for i := range mappings {
+			mappings[i].From = rule.transformValue(mappings[i].From)
+			mappings[i].To = rule.transformValue(mappings[i].To)
+		}
+		rule.Mappings = mappings
+		if err := checkValueMappings(rule); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+// createConstraintRule creates parameter rule using plugins or standard parsing
+func (rp *RuleParser) createConstraintRule(paramName, constraintStr string) (*ParamRule, error) {
 	rule := &ParamRule{Name: paramName}
 
 	// Try plugins first
@@ -674,6 +1486,7 @@ func (rp *RuleParser) createParamRule(paramName, constraintStr string) (*ParamRu
 	if pluginUsed {
 		rule.Pattern = "plugin"
 		rule.CustomValidator = validatorFunc
+		rule.Repair = rp.tryRepairPlugins(paramName, constraintStr)
 		rule.ConstraintStr = constraintStr
 		return rule, nil
 	}
@@ -730,6 +1543,20 @@ func (rp *RuleParser) tryPlugins(paramName, constraintStr string) (func(string)
 	return nil, false, nil
 }
 
+// tryRepairPlugins returns repair function of first plugin accepting constraint, nil if none
+func (rp *RuleParser) tryRepairPlugins(paramName, constraintStr string) func(string) (string, bool) {
+	for _, plugin := range rp.plugins {
+		repairer, ok := plugin.(PluginRepairParser)
+		if !ok {
+			continue
+		}
+		if repairFunc, err := repairer.ParseRepair(paramName, constraintStr); err == nil {
+			return repairFunc
+		}
+	}
+	return nil
+}
+
 // isNotForPluginError checks if error indicates that constraint is not for this plugin
 func isNotForPluginError(err error) bool {
 	if err == nil {
@@ -811,12 +1638,12 @@ func (rp *RuleParser) CheckRulesSyntax(rulesStr string) error {
 		return err
 	}
 
-	globalParams, urlRules, err := rp.parseRulesUnsafe(rulesStr)
+	parsed, err := rp.parseRuleSetUnsafe(rulesStr)
 	if err != nil {
 		return err
 	}
 
-	return rp.testPluginValidation(globalParams, urlRules)
+	return rp.testPluginValidation(parsed.globalParams, parsed.urlRules)
 }
 
 // testPluginValidation tests plugin validation functions for all constraints
@@ -153,6 +153,18 @@ func TestLengthPlugin(t *testing.T) {
 			value:      "",
 			expected:   true,
 		},
+		{
+			name:       "open lower bound valid",
+			constraint: "len:..5",
+			value:      "",
+			expected:   true,
+		},
+		{
+			name:       "open lower bound invalid",
+			constraint: "len:..5",
+			value:      "hello!",
+			expected:   false,
+		},
 		{
 			name:       "very long string",
 			constraint: "len:<1000",
@@ -3,6 +3,7 @@ package plugins
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -23,45 +24,81 @@ func (cp *ComparisonPlugin) GetName() string {
 }
 
 func (cp *ComparisonPlugin) Parse(paramName, constraintStr string) (func(string) bool, error) {
+	operator, threshold, err := cp.parseExpression(constraintStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return cp.createValidator(operator, threshold), nil
+}
+
+func (cp *ComparisonPlugin) ParseRepair(paramName, constraintStr string) (func(string) (string, bool), error) {
+	operator, threshold, err := cp.parseExpression(constraintStr)
+	if err != nil {
+		return nil, err
+	}
+
+	min, max := -maxComparisonValue, maxComparisonValue
+	switch operator {
+	case ">":
+		min = threshold + 1
+	case ">=":
+		min = threshold
+	case "<":
+		max = threshold - 1
+	case "<=":
+		max = threshold
+	}
+
+	return func(value string) (string, bool) {
+		num, ok := parseNumber(value)
+		if !ok {
+			return "", false
+		}
+		return strconv.Itoa(clamp(num, min, max)), true
+	}, nil
+}
+
+func (cp *ComparisonPlugin) parseExpression(constraintStr string) (string, int, error) {
 	if len(constraintStr) == 0 {
-		return nil, fmt.Errorf("not for this plugin: empty constraint")
+		return "", 0, fmt.Errorf("not for this plugin: empty constraint")
 	}
 
 	prefix := cp.name + ":"
 	if len(constraintStr) < len(prefix) || !strings.HasPrefix(constraintStr, prefix) {
-		return nil, fmt.Errorf("not for this plugin: comparison constraint must start with '%s:'", cp.name)
+		return "", 0, fmt.Errorf("not for this plugin: comparison constraint must start with '%s:'", cp.name)
 	}
 
 	rest := strings.TrimSpace(constraintStr[len(prefix):])
 	if rest == "" {
-		return nil, fmt.Errorf("empty comparison expression")
+		return "", 0, fmt.Errorf("empty comparison expression")
 	}
 
 	operator, numStart := cp.parseOperator(rest)
 	if operator == "" {
-		return nil, fmt.Errorf("invalid operator format: must start with >, <, >=, or <=")
+		return "", 0, fmt.Errorf("invalid operator format: must start with >, <, >=, or <=")
 	}
 
 	if numStart >= len(rest) {
-		return nil, fmt.Errorf("missing number value after operator")
+		return "", 0, fmt.Errorf("missing number value after operator")
 	}
 
 	numStr := strings.TrimSpace(rest[numStart:])
 	if numStr == "" {
-		return nil, fmt.Errorf("missing number value")
+		return "", 0, fmt.Errorf("missing number value")
 	}
 
 	threshold, ok := parseNumber(numStr)
 	if !ok {
-		return nil, fmt.Errorf("invalid number format: '%s'", numStr)
+		return "", 0, fmt.Errorf("invalid number format: '%s'", numStr)
 	}
 
 	if threshold > maxComparisonValue || threshold < -maxComparisonValue {
-		return nil, fmt.Errorf("value out of range: %d (allowed: -%d to %d)",
+		return "", 0, fmt.Errorf("value out of range: %d (allowed: -%d to %d)",
 			threshold, maxComparisonValue, maxComparisonValue)
 	}
 
-	return cp.createValidator(operator, threshold), nil
+	return operator, threshold, nil
 }
 
 func (cp *ComparisonPlugin) parseOperator(str string) (string, int) {
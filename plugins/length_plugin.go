@@ -39,6 +39,45 @@ func (lp *LengthPlugin) Parse(paramName, constraintStr string) (func(string) boo
 	return validator, nil
 }
 
+func (lp *LengthPlugin) ParseRepair(paramName, constraintStr string) (func(string) (string, bool), error) {
+	validator, err := lp.Parse(paramName, constraintStr)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLength, ok := lp.parseMaxLength(strings.TrimSpace(constraintStr[len(lp.name)+1:]))
+	if !ok {
+		// Values can only be shortened, constraints without upper bound have no repair
+		return nil, nil
+	}
+
+	return func(value string) (string, bool) {
+		truncated := truncateRunes(value, maxLength)
+		return truncated, validator(truncated)
+	}, nil
+}
+
+func (lp *LengthPlugin) parseMaxLength(rest string) (int, bool) {
+	if dotPos := lp.findDoubleDot(rest); dotPos != -1 {
+		return parseNumber(strings.TrimSpace(rest[dotPos+2:]))
+	}
+
+	operator, numStart := lp.parseOperator(rest)
+	length, ok := parseNumber(strings.TrimSpace(rest[numStart:]))
+	if !ok {
+		return 0, false
+	}
+
+	switch operator {
+	case "", "=", "<=":
+		return length, true
+	case "<":
+		return length - 1, length > 0
+	default:
+		return 0, false
+	}
+}
+
 func (lp *LengthPlugin) parseConstraint(rest string) (func(string) bool, error) {
 	if dotPos := lp.findDoubleDot(rest); dotPos != -1 {
 		return lp.parseRange(rest, dotPos)
@@ -59,7 +98,12 @@ func (lp *LengthPlugin) parseRange(s string, dotPos int) (func(string) bool, err
 	minStr := strings.TrimSpace(s[:dotPos])
 	maxStr := strings.TrimSpace(s[dotPos+2:])
 
-	if minStr == "" || maxStr == "" {
+	// Open lower bound "..max" is shorthand for "0..max"
+	if minStr == "" {
+		minStr = "0"
+	}
+
+	if maxStr == "" {
 		return nil, fmt.Errorf("invalid range format: '%s'", s)
 	}
 
@@ -3,6 +3,7 @@ package plugins
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -24,14 +25,50 @@ func (rp *RangePlugin) GetName() string {
 }
 
 func (rp *RangePlugin) Parse(paramName, constraintStr string) (func(string) bool, error) {
+	min, max, err := rp.parseBounds(constraintStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value string) bool {
+		if len(value) > maxRangeNumberLength {
+			return false
+		}
+		num, ok := parseNumber(value)
+		if !ok {
+			return false
+		}
+		if num > maxRangeValue || num < -maxRangeValue {
+			return false
+		}
+		return num >= min && num <= max
+	}, nil
+}
+
+func (rp *RangePlugin) ParseRepair(paramName, constraintStr string) (func(string) (string, bool), error) {
+	min, max, err := rp.parseBounds(constraintStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value string) (string, bool) {
+		num, ok := parseNumber(value)
+		if !ok {
+			return "", false
+		}
+		return strconv.Itoa(clamp(num, min, max)), true
+	}, nil
+}
+
+func (rp *RangePlugin) parseBounds(constraintStr string) (int, int, error) {
 	prefix := rp.name + ":"
 	if len(constraintStr) < len(prefix) || !strings.HasPrefix(constraintStr, prefix) {
-		return nil, fmt.Errorf("not for this plugin: range constraint must start with '%s:'", rp.name)
+		return 0, 0, fmt.Errorf("not for this plugin: range constraint must start with '%s:'", rp.name)
 	}
 
 	rest := strings.TrimSpace(constraintStr[6:])
 	if len(rest) < 3 {
-		return nil, fmt.Errorf("not for this plugin: range too short")
+		return 0, 0, fmt.Errorf("not for this plugin: range too short")
 	}
 
 	// Find separator in single pass
@@ -51,7 +88,7 @@ func (rp *RangePlugin) Parse(paramName, constraintStr string) (func(string) bool
 	}
 
 	if sepPos == -1 {
-		return nil, fmt.Errorf("invalid range format: %s", constraintStr)
+		return 0, 0, fmt.Errorf("invalid range format: %s", constraintStr)
 	}
 
 	var minStr, maxStr string
@@ -64,42 +101,30 @@ func (rp *RangePlugin) Parse(paramName, constraintStr string) (func(string) bool
 	}
 
 	if minStr == "" || maxStr == "" {
-		return nil, fmt.Errorf("invalid range format: %s", constraintStr)
+		return 0, 0, fmt.Errorf("invalid range format: %s", constraintStr)
 	}
 
 	if len(minStr) > maxRangeNumberLength || len(maxStr) > maxRangeNumberLength {
-		return nil, fmt.Errorf("number too long in range: %s", constraintStr)
+		return 0, 0, fmt.Errorf("number too long in range: %s", constraintStr)
 	}
 
 	min, minOk := parseNumber(minStr)
 	max, maxOk := parseNumber(maxStr)
 
 	if !minOk || !maxOk {
-		return nil, fmt.Errorf("invalid range: %s", constraintStr)
+		return 0, 0, fmt.Errorf("invalid range: %s", constraintStr)
 	}
 
 	if min > max {
-		return nil, fmt.Errorf("invalid range: %d..%d (min > max)", min, max)
+		return 0, 0, fmt.Errorf("invalid range: %d..%d (min > max)", min, max)
 	}
 
 	if min > maxRangeValue || max > maxRangeValue || min < -maxRangeValue || max < -maxRangeValue {
-		return nil, fmt.Errorf("range values out of range: %d..%d (allowed: -%d to %d)",
+		return 0, 0, fmt.Errorf("range values out of range: %d..%d (allowed: -%d to %d)",
 			min, max, maxRangeValue, maxRangeValue)
 	}
 
-	return func(value string) bool {
-		if len(value) > maxRangeNumberLength {
-			return false
-		}
-		num, ok := parseNumber(value)
-		if !ok {
-			return false
-		}
-		if num > maxRangeValue || num < -maxRangeValue {
-			return false
-		}
-		return num >= min && num <= max
-	}, nil
+	return min, max, nil
 }
 
 func (rp *RangePlugin) Close() error {
@@ -47,3 +47,24 @@ func stringLength(s string) int {
 	}
 	return len(s)
 }
+
+func clamp(num, min, max int) int {
+	if num < min {
+		return min
+	}
+	if num > max {
+		return max
+	}
+	return num
+}
+
+func truncateRunes(s string, maxRunes int) string {
+	count := 0
+	for i := range s {
+		if count == maxRunes {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+// repair.go
+package paramvalidator
+
+// WithRepair makes filtering replace rejected values with fix-ups instead of dropping parameters
+// Plugin rules are repaired by plugins implementing PluginRepairParser, rules with default value
+// fall back to it, detailed validation reports the fix-up of every repairable violation
+func WithRepair(enabled bool) Option {
+	return func(pv *ParamValidator) {
+		pv.repair = enabled
+	}
+}
+
+// repairValue returns fix-up for value rejected by rule
+// Inverted rules are never repaired, plugin fix-ups failing the rule are discarded
+func (rs *ruleSnapshot) repairValue(rule *ParamRule, value string) (string, bool) {
+	if rule == nil || rule.Inverted {
+		return "", false
+	}
+	value = rule.transformValue(value)
+	if rule.Repair != nil {
+		if repaired, ok := rs.safeRepair(rule.Repair, value); ok && rs.checkValue(rule, repaired, false) == ViolationNone {
+			return repaired, true
+		}
+	}
+	if rule.HasDefault {
+		return rule.transformValue(rule.Default), true
+	}
+	return "", false
+}
+
+// safeRepair executes repair function with panic protection
+func (rs *ruleSnapshot) safeRepair(repair func(string) (string, bool), value string) (repaired string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			repaired, ok = "", false
+		}
+	}()
+	return repair(value)
+}
+
+// appendRepairedSegment appends segment rejected by checked rule with its value replaced by fix-up
+func appendRepairedSegment[T ~string | ~[]byte](rs *ruleSnapshot, dst []byte, segment T, check segmentCheck) ([]byte, bool) {
+	if !rs.repair || check.rule == nil || check.allowed {
+		return dst, false
+	}
+
+	var valueBuf [64]byte
+	repaired, ok := rs.repairValue(check.rule, string(appendSegmentValue(valueBuf[:0], segment, rs.decodingMode)))
+	if !ok {
+		return dst, false
+	}
+	return appendSegmentWithValue(rs, dst, segment, rewrittenKey(rs, segment, check.rule), repaired), true
+}
+
+// appendSegmentWithValue appends key of segment followed by new value
+// Value is encoded when decoding is enabled
+func appendSegmentWithValue[T, V ~string | ~[]byte](rs *ruleSnapshot, dst []byte, segment T, name string, value V) []byte {
+	dst, _ = appendSegmentKey(rs, dst, segment, name)
+	dst = append(dst, '=')
+	if rs.decodingMode == DecodeNone {
+		return append(dst, value...)
+	}
+	for i := 0; i < len(value); i++ {
+		dst = appendEscapedByte(dst, value[i])
+	}
+	return dst
+}
+
+// appendSegmentKey appends key of segment in its original encoding, or name replacing it when not empty
+// Name is encoded when decoding is enabled, end of original key in segment is returned
+func appendSegmentKey[T ~string | ~[]byte](rs *ruleSnapshot, dst []byte, segment T, name string) ([]byte, int) {
+	keyEnd := len(segment)
+	for i := 0; i < len(segment); i++ {
+		if segment[i] == '=' {
+			keyEnd = i
+			break
+		}
+	}
+
+	switch {
+	case name == "":
+		dst = append(dst, segment[:keyEnd]...)
+	case rs.decodingMode == DecodeNone:
+		dst = append(dst, name...)
+	default:
+		for i := 0; i < len(name); i++ {
+			dst = appendEscapedByte(dst, name[i])
+		}
+	}
+	return dst, keyEnd
+}
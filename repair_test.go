@@ -0,0 +1,259 @@
+package paramvalidator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+const repairRules = "/list?page=[range:1..100]&limit=[cmp:<=50]&q=[len:..5]&sort=[name,date]=name&mode=[a,b]&id=![range:1..5]&tag=[len:>2]"
+
+var repairPlugins = WithPlugins(plugins.NewRangePlugin(), plugins.NewComparisonPlugin(), plugins.NewLengthPlugin())
+
+// panicRepairPlugin accepts "boom" constraint whose repair function panics
+type panicRepairPlugin struct{}
+
+func (panicRepairPlugin) GetName() string { return "boom" }
+
+func (panicRepairPlugin) Parse(_, constraintStr string) (func(string) bool, error) {
+	if constraintStr != "boom" {
+		return nil, fmt.Errorf("not for this plugin")
+	}
+	return func(value string) bool { return value == "ok" }, nil
+}
+
+func (panicRepairPlugin) ParseRepair(_, _ string) (func(string) (string, bool), error) {
+	return func(string) (string, bool) { panic("repair failed") }, nil
+}
+
+func TestRepairFilterURL(t *testing.T) {
+	pv := newTestValidator(t, repairRules, repairPlugins, WithRepair(true))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/list?page=1000", "/list?page=100"},
+		{"/list?page=0&limit=80", "/list?page=1&limit=50"},
+		{"/list?page=abc&limit=-3", "/list?limit=-3"},
+		{"/list?q=abcdefgh", "/list?q=abcde"},
+		{"/list?sort=price&mode=c", "/list?sort=name"},
+		{"/list?sort", "/list?sort=name"},
+		{"/list?id=3&tag=a", "/list"},
+		{"/list?page=5&debug=1", "/list?page=5"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	// Repaired values are still violations for validation
+	if pv.ValidateURL("/list?page=1000") {
+		t.Error("Expected out of range page to stay invalid")
+	}
+}
+
+func TestRepairDisabled(t *testing.T) {
+	pv := newTestValidator(t, repairRules, repairPlugins)
+
+	if result := pv.FilterURL("/list?page=1000&sort=price&limit=10"); result != "/list?limit=10" {
+		t.Errorf("FilterURL = %q, expected rejected params to be dropped", result)
+	}
+}
+
+func TestRepairFilterQueryBytes(t *testing.T) {
+	pv := newTestValidator(t, repairRules, repairPlugins, WithRepair(true))
+
+	buffer := make([]byte, 0, 64)
+	if result := pv.FilterQueryBytes([]byte("/list"), []byte("page=1000&limit=80&mode=c"), buffer); string(result) != "page=100&limit=50" {
+		t.Errorf("FilterQueryBytes = %q, expected repaired query", result)
+	}
+	if result := pv.FilterQuery("/list", "q=abcdefgh&page=-1"); result != "q=abcde&page=1" {
+		t.Errorf("FilterQuery = %q, expected repaired query", result)
+	}
+
+	// Repaired value longer than the original must still fit into buffer
+	if result := pv.FilterQueryBytes([]byte("/list"), []byte("sort=x"), make([]byte, 0, 6)); result != nil {
+		t.Errorf("FilterQueryBytes = %q, expected nil for buffer too small for repaired query", result)
+	}
+}
+
+func TestRepairFilterQueryBytesGrowingValue(t *testing.T) {
+	pv := newTestValidator(t, "/list?n=[cmp:>100]&m=[*]", repairPlugins, WithRepair(true))
+
+	// Comparison repairs 5 to 101, two bytes longer than the original
+	tests := []struct {
+		query    string
+		capacity int
+		expected string
+	}{
+		{"n=5", 3, ""},
+		{"n=5", 5, "n=101"},
+		{"n=5&m=1", 7, ""},
+		{"n=5&m=1", 8, ""},
+		{"n=5&m=1", 9, "n=101&m=1"},
+		{"m=1&n=5", 8, ""},
+		{"m=1&n=5", 9, "m=1&n=101"},
+	}
+
+	for _, tt := range tests {
+		buffer := make([]byte, 0, tt.capacity)
+		result := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), buffer)
+		if string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q, cap %d) = %q, expected %q", tt.query, tt.capacity, result, tt.expected)
+			continue
+		}
+		if tt.expected != "" && !usesBuffer(result, buffer) {
+			t.Errorf("FilterQueryBytes(%q) result does not share caller's buffer", tt.query)
+		}
+	}
+}
+
+func TestRepairDecoding(t *testing.T) {
+	pv := newTestValidator(t, repairRules, repairPlugins, WithRepair(true), WithDecoding(DecodeQuery))
+
+	if result := pv.FilterQuery("/list", "q=a+b+c+d&%70age=200"); result != "q=a%20b%20c&%70age=100" {
+		t.Errorf("FilterQuery = %q, expected repaired values re-encoded", result)
+	}
+}
+
+func TestRepairDetailed(t *testing.T) {
+	pv := newTestValidator(t, repairRules, repairPlugins, WithRepair(true))
+
+	result := pv.ValidateURLDetailed("/list?page=1000&mode=c&sort=price")
+	if result.Valid {
+		t.Fatal("Expected invalid result")
+	}
+
+	expected := map[string]struct {
+		repaired  string
+		hasRepair bool
+	}{
+		"page": {"100", true},
+		"mode": {"", false},
+		"sort": {"name", true},
+	}
+	if len(result.Violations) != len(expected) {
+		t.Fatalf("Got %d violations, expected %d: %+v", len(result.Violations), len(expected), result.Violations)
+	}
+	for _, v := range result.Violations {
+		want, ok := expected[v.Param]
+		if !ok {
+			t.Errorf("Unexpected violation %+v", v)
+			continue
+		}
+		if v.HasRepair != want.hasRepair || v.Repaired != want.repaired {
+			t.Errorf("Violation %s repair = (%q, %v), expected (%q, %v)", v.Param, v.Repaired, v.HasRepair, want.repaired, want.hasRepair)
+		}
+	}
+}
+
+func TestRepairNormalizeURL(t *testing.T) {
+	pv := newTestValidator(t, "/list?page=[range:1..100]=1&q=[*]", WithPlugins(plugins.NewRangePlugin()), WithRepair(true))
+
+	if result := pv.NormalizeURL("/list?page=1000&q=a"); result != "/list?page=100&q=a" {
+		t.Errorf("NormalizeURL = %q, expected repaired page", result)
+	}
+	if result := pv.NormalizeURL("/list?page=x&q=a"); result != "/list?page=1&q=a" {
+		t.Errorf("NormalizeURL = %q, expected default page", result)
+	}
+}
+
+func TestRepairRequired(t *testing.T) {
+	pv := newTestValidator(t, "/list?+page=[range:1..10]&q=[*]", WithPlugins(plugins.NewRangePlugin()), WithRepair(true))
+
+	// Repaired required parameter counts as present
+	if result := pv.FilterURL("/list?page=50&q=a"); result != "/list?page=10&q=a" {
+		t.Errorf("FilterURL = %q, expected repaired required page", result)
+	}
+	if result := pv.FilterURL("/list?page=x&q=a"); result != "" {
+		t.Errorf("FilterURL = %q, expected unrepairable required page to reject URL", result)
+	}
+}
+
+func TestRepairDuplicates(t *testing.T) {
+	pv := newTestValidator(t, "/list?n=[range:1..5]@first&m=[range:1..5]@last",
+		WithPlugins(plugins.NewRangePlugin()), WithRepair(true))
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/list?n=9&n=2", "/list?n=5"},
+		{"/list?n=2&n=9", "/list?n=2"},
+		{"/list?m=2&m=9", "/list?m=5"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestRepairPanicFallsBackToDefault(t *testing.T) {
+	pv := newTestValidator(t, "/list?p=[boom]&d=[boom]=ok", WithPlugins(panicRepairPlugin{}), WithRepair(true))
+
+	if result := pv.FilterURL("/list?p=bad&d=bad"); result != "/list?d=ok" {
+		t.Errorf("FilterURL = %q, expected panicking repair to fall back to default", result)
+	}
+	result := pv.ValidateURLDetailed("/list?p=bad")
+	if len(result.Violations) != 1 || result.Violations[0].HasRepair {
+		t.Errorf("Violations = %+v, expected no repair for p", result.Violations)
+	}
+}
+
+func TestRepairAfterTransforms(t *testing.T) {
+	pv := newTestValidator(t, "/list?q=[upper|len:..3]", WithPlugins(plugins.NewLengthPlugin()), WithRepair(true))
+
+	if result := pv.FilterURL("/list?q=abcdef"); result != "/list?q=ABC" {
+		t.Errorf("FilterURL = %q, expected transformed value to be repaired", result)
+	}
+	result := pv.ValidateURLDetailed("/list?q=abcdef")
+	if len(result.Violations) != 1 || result.Violations[0].Repaired != "ABC" {
+		t.Errorf("Violations = %+v, expected repair of transformed value", result.Violations)
+	}
+}
+
+func TestPluginRepair(t *testing.T) {
+	tests := []struct {
+		plugin     PluginRepairParser
+		constraint string
+		value      string
+		repaired   string
+		ok         bool
+	}{
+		{plugins.NewRangePlugin(), "range:1..100", "1000", "100", true},
+		{plugins.NewRangePlugin(), "range:-20..40", "-50", "-20", true},
+		{plugins.NewRangePlugin(), "range:1..100", "abc", "", false},
+		{plugins.NewComparisonPlugin(), "cmp:>18", "5", "19", true},
+		{plugins.NewComparisonPlugin(), "cmp:<1000", "5000", "999", true},
+		{plugins.NewComparisonPlugin(), "cmp:>=0", "-1", "0", true},
+		{plugins.NewLengthPlugin(), "len:..3", "abcdef", "abc", true},
+		{plugins.NewLengthPlugin(), "len:2..4", "приветик", "прив", true},
+		{plugins.NewLengthPlugin(), "len:<3", "abcd", "ab", true},
+		{plugins.NewLengthPlugin(), "len:8..20", "short", "short", false},
+	}
+
+	for _, tt := range tests {
+		repair, err := tt.plugin.ParseRepair("p", tt.constraint)
+		if err != nil || repair == nil {
+			t.Errorf("ParseRepair(%q) error = %v, expected repair function", tt.constraint, err)
+			continue
+		}
+		if repaired, ok := repair(tt.value); repaired != tt.repaired || ok != tt.ok {
+			t.Errorf("repair %q (%q) = (%q, %v), expected (%q, %v)", tt.constraint, tt.value, repaired, ok, tt.repaired, tt.ok)
+		}
+	}
+
+	// Lengths can only be shortened, so lower bounds have no repair
+	if repair, err := plugins.NewLengthPlugin().ParseRepair("p", "len:>5"); err != nil || repair != nil {
+		t.Errorf("ParseRepair(len:>5) error = %v, expected no repair function", err)
+	}
+	if _, err := plugins.NewRangePlugin().ParseRepair("p", "cmp:>5"); err == nil {
+		t.Error("Expected range plugin to refuse comparison constraint")
+	}
+}
@@ -0,0 +1,97 @@
+// report.go
+package paramvalidator
+
+import (
+	"net/url"
+)
+
+// ReportOperation identifies call whose verdict report-only mode did not enforce
+type ReportOperation int
+
+const (
+	// ReportValidate reports URL or query validation would reject
+	ReportValidate ReportOperation = iota
+	// ReportFilter reports URL or query filtering would change
+	ReportFilter
+	// ReportNormalize reports URL normalization would change
+	ReportNormalize
+)
+
+// String returns human readable name of report operation
+func (ro ReportOperation) String() string {
+	switch ro {
+	case ReportValidate:
+		return "validate"
+	case ReportFilter:
+		return "filter"
+	case ReportNormalize:
+		return "normalize"
+	default:
+		return "unknown"
+	}
+}
+
+// Report describes verdict report-only mode did not enforce
+type Report struct {
+	Operation ReportOperation
+	// URL is complete URL or URL path joined with query by '?'
+	URL string
+	// Filtered is result filtering or normalization would return, empty for validation
+	Filtered string
+	// Result holds every violation found in URL
+	Result ValidationResult
+}
+
+// ReportObserver receives verdicts report-only mode did not enforce
+// It is called after verdict is computed, so it may load rules
+type ReportObserver func(report Report)
+
+// WithReportOnly makes validation accept and filtering keep every URL
+// Rejections and removals are passed to observer instead, nil observer enforces verdicts
+func WithReportOnly(observer ReportObserver) Option {
+	return func(pv *ParamValidator) {
+		pv.reportObserver = observer
+	}
+}
+
+// reportURL reports unenforced verdict for complete URL
+// Violations are collected without observers, enforced call has already raised its warnings
+func (rs *ruleSnapshot) reportURL(operation ReportOperation, fullURL, filtered string) {
+	rs.safeReport(Report{
+		Operation: operation,
+		URL:       fullURL,
+		Filtered:  filtered,
+		Result:    rs.withoutObservers().validateURLDetailed(fullURL),
+	})
+}
+
+// reportQuery reports unenforced verdict for query of URL path
+func (rs *ruleSnapshot) reportQuery(operation ReportOperation, urlPath, queryString, filtered string) {
+	fullURL := urlPath
+	if queryString != "" {
+		fullURL += "?" + queryString
+	}
+	rs.safeReport(Report{
+		Operation: operation,
+		URL:       fullURL,
+		Filtered:  filtered,
+		Result:    rs.withoutObservers().validateQueryDetailed(urlPath, queryString),
+	})
+}
+
+// reportParam reports unenforced verdict for single parameter of URL path
+func (rs *ruleSnapshot) reportParam(urlPath, paramName, paramValue string) {
+	rs.safeReport(Report{
+		Operation: ReportValidate,
+		URL:       urlPath + "?" + url.QueryEscape(paramName) + "=" + url.QueryEscape(paramValue),
+		Result:    rs.withoutObservers().validateParamDetailed(urlPath, paramName, paramValue),
+	})
+}
+
+// safeReport executes report observer with panic protection
+func (rs *ruleSnapshot) safeReport(report Report) {
+	defer func() {
+		_ = recover()
+	}()
+	rs.reportObserver(report)
+}
@@ -0,0 +1,229 @@
+package paramvalidator
+
+import (
+	"testing"
+)
+
+const reportRules = "/list?page=[1,2,3]&sort=[asc,desc]"
+
+// collectReports enables report-only mode appending reports to reports
+func collectReports(reports *[]Report) Option {
+	return WithReportOnly(func(report Report) {
+		*reports = append(*reports, report)
+	})
+}
+
+func TestReportOnlyValidate(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, reportRules, collectReports(&reports))
+
+	calls := []struct {
+		name string
+		call func(query string) bool
+	}{
+		{"ValidateURL", func(query string) bool { return pv.ValidateURL("/list?" + query) }},
+		{"ValidateQuery", func(query string) bool { return pv.ValidateQuery("/list", query) }},
+		{"ValidateQueryBytes", func(query string) bool { return pv.ValidateQueryBytes([]byte("/list"), []byte(query)) }},
+	}
+
+	for _, tc := range calls {
+		reports = nil
+		if !tc.call("page=1&sort=asc") {
+			t.Errorf("%s: expected valid query to be accepted", tc.name)
+		}
+		if len(reports) != 0 {
+			t.Errorf("%s: expected no report for valid query, got %+v", tc.name, reports)
+		}
+
+		if !tc.call("page=20&sort=up") {
+			t.Errorf("%s: expected invalid query to be accepted in report-only mode", tc.name)
+		}
+		if len(reports) != 1 {
+			t.Errorf("%s: got %d reports, expected 1", tc.name, len(reports))
+			continue
+		}
+		report := reports[0]
+		if report.Operation != ReportValidate || report.URL != "/list?page=20&sort=up" || report.Filtered != "" {
+			t.Errorf("%s: unexpected report %+v", tc.name, report)
+		}
+		if report.Result.Valid || len(report.Result.Violations) != 2 {
+			t.Errorf("%s: expected two violations, got %+v", tc.name, report.Result)
+		}
+	}
+}
+
+func TestReportOnlyFilter(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, reportRules, collectReports(&reports))
+
+	calls := []struct {
+		name string
+		call func(query string) string
+	}{
+		{"FilterURL", func(query string) string { return pv.FilterURL("/list?" + query)[len("/list?"):] }},
+		{"FilterQuery", func(query string) string { return pv.FilterQuery("/list", query) }},
+		{"FilterQueryBytes", func(query string) string {
+			return string(pv.FilterQueryBytes([]byte("/list"), []byte(query), make([]byte, 0, 64)))
+		}},
+	}
+
+	for _, tc := range calls {
+		reports = nil
+		if result := tc.call("page=1&sort=asc"); result != "page=1&sort=asc" || len(reports) != 0 {
+			t.Errorf("%s: got %q with reports %+v for valid query", tc.name, result, reports)
+		}
+
+		query := "page=1&sort=up&debug=1"
+		if result := tc.call(query); result != query {
+			t.Errorf("%s: got %q, expected query unchanged in report-only mode", tc.name, result)
+		}
+		if len(reports) != 1 {
+			t.Errorf("%s: got %d reports, expected 1", tc.name, len(reports))
+			continue
+		}
+		report := reports[0]
+		if report.Operation != ReportFilter || report.URL != "/list?"+query {
+			t.Errorf("%s: unexpected report %+v", tc.name, report)
+		}
+		if report.Filtered != "page=1" && report.Filtered != "/list?page=1" {
+			t.Errorf("%s: Filtered = %q", tc.name, report.Filtered)
+		}
+		if len(report.Result.Violations) != 2 {
+			t.Errorf("%s: expected two violations, got %+v", tc.name, report.Result.Violations)
+		}
+	}
+}
+
+func TestReportOnlyFilterQueryBytesSmallBuffer(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, reportRules, collectReports(&reports))
+
+	query := []byte("page=1&debug=1")
+	if result := pv.FilterQueryBytes([]byte("/list"), query, make([]byte, 0, 8)); result != nil {
+		t.Errorf("Expected nil when buffer cannot hold query, got %q", result)
+	}
+	if len(reports) != 1 || len(reports[0].Result.Violations) != 1 {
+		t.Errorf("Expected rejected param to be reported, got %+v", reports)
+	}
+}
+
+func TestReportOnlyObserverPanic(t *testing.T) {
+	pv := newTestValidator(t, reportRules, WithReportOnly(func(Report) {
+		panic("observer failure")
+	}))
+
+	if !pv.ValidateURL("/list?page=20") {
+		t.Error("Expected panicking observer not to affect verdict")
+	}
+	if result := pv.FilterURL("/list?page=20"); result != "/list?page=20" {
+		t.Errorf("FilterURL = %q, expected URL unchanged", result)
+	}
+}
+
+func TestReportOnlyValidateParam(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, reportRules, collectReports(&reports))
+
+	if !pv.ValidateParam("/list", "page", "1") || len(reports) != 0 {
+		t.Errorf("Expected valid param to be accepted without report, got %+v", reports)
+	}
+	if !pv.ValidateParam("/list", "page", "a b") {
+		t.Error("Expected invalid param to be accepted in report-only mode")
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Got %d reports, expected 1", len(reports))
+	}
+	report := reports[0]
+	if report.Operation != ReportValidate || report.URL != "/list?page=a+b" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if report.Result.Valid || len(report.Result.Violations) != 1 ||
+		report.Result.Violations[0].Kind != ViolationEnumMismatch || report.Result.Violations[0].Value != "a b" {
+		t.Errorf("Expected enum mismatch of page, got %+v", report.Result)
+	}
+}
+
+func TestReportOnlyNormalize(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, "/list?page=[1,2,3]=1", collectReports(&reports))
+
+	if result := pv.NormalizeURL("/list?page=2"); result != "/list?page=2" || len(reports) != 0 {
+		t.Errorf("NormalizeURL = %q with reports %+v, expected normalized URL unchanged", result, reports)
+	}
+	if result := pv.NormalizeURL("/list?page=7&debug=1"); result != "/list?page=7&debug=1" {
+		t.Errorf("NormalizeURL = %q, expected URL unchanged in report-only mode", result)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Got %d reports, expected 1", len(reports))
+	}
+	report := reports[0]
+	if report.Operation != ReportNormalize || report.Filtered != "/list?page=1" || len(report.Result.Violations) != 2 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestReportOnlyWarnsOnce(t *testing.T) {
+	var reports []Report
+	var warnings []Warning
+	pv := newTestValidator(t, "/list?page=[1,2,3]&~sort=[asc]", collectReports(&reports),
+		WithWarningObserver(func(_ string, warning Warning) {
+			warnings = append(warnings, warning)
+		}))
+
+	if !pv.ValidateURL("/list?sort=asc&page=7") {
+		t.Error("Expected report-only mode to accept URL")
+	}
+	if len(reports) != 1 || len(reports[0].Result.Warnings) != 1 {
+		t.Errorf("Reports = %+v, expected one report listing deprecated sort", reports)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Observer got %d warnings, expected deprecated sort reported once", len(warnings))
+	}
+}
+
+func TestReportOnlyDetailedEnforced(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, reportRules, collectReports(&reports))
+
+	if result := pv.ValidateURLDetailed("/list?page=20"); result.Valid || len(result.Violations) != 1 {
+		t.Errorf("ValidateURLDetailed = %+v, expected enforced verdict", result)
+	}
+	if result := pv.ValidateQueryDetailed("/list", "page=20"); result.Valid {
+		t.Errorf("ValidateQueryDetailed = %+v, expected enforced verdict", result)
+	}
+	if len(reports) != 0 {
+		t.Errorf("Expected detailed calls not to report, got %+v", reports)
+	}
+}
+
+func TestReportOnlyDisabled(t *testing.T) {
+	pv := newTestValidator(t, reportRules, WithReportOnly(nil))
+
+	if pv.ValidateURL("/list?page=20") {
+		t.Error("Expected nil observer to keep verdicts enforced")
+	}
+	if result := pv.FilterQuery("/list", "page=1&debug=1"); result != "page=1" {
+		t.Errorf("FilterQuery = %q, expected %q", result, "page=1")
+	}
+}
+
+func TestReportOnlyRewrite(t *testing.T) {
+	var reports []Report
+	pv := newTestValidator(t, "/list?page=[1,2,3]&sort=[lower|asc,desc; up->asc]", collectReports(&reports))
+
+	// Query filtering would only rewrite is reported as change, though nothing is rejected
+	if result := pv.FilterQuery("/list", "sort=up&page=1"); result != "sort=up&page=1" {
+		t.Errorf("FilterQuery = %q, expected query unchanged in report-only mode", result)
+	}
+	if !pv.ValidateURL("/list?sort=UP") || len(reports) != 1 {
+		t.Fatalf("Reports = %+v, expected only filtering reported", reports)
+	}
+	report := reports[0]
+	if report.Operation != ReportFilter || report.Filtered != "sort=asc&page=1" || !report.Result.Valid {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	expected := Rewrite{Param: "sort", Value: "up", Rewritten: "asc"}
+	if len(report.Result.Rewrites) != 1 || report.Result.Rewrites[0] != expected {
+		t.Errorf("Rewrites = %+v, expected %+v", report.Result.Rewrites, expected)
+	}
+}
@@ -0,0 +1,85 @@
+// required.go
+package paramvalidator
+
+// requiredMaskForURL returns mask of parameters that must be present for URL path
+// Required globals apply unless the most specific URL rule redefines them
+func (rs *ruleSnapshot) requiredMaskForURL(urlPath string) ParamMask {
+	if rs.compiledRules == nil || !rs.compiledRules.hasRequired {
+		return NewParamMask()
+	}
+
+	required := rs.compiledRules.globalRequiredMask
+	if mostSpecificRule := rs.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
+		required = required.Difference(mostSpecificRule.ParamMask).Union(mostSpecificRule.requiredMask)
+	}
+	return required
+}
+
+// requiredMaskWithVariant returns required mask for URL path extended by selected variant
+func (rs *ruleSnapshot) requiredMaskWithVariant(masks ParamMasks, urlPath string) ParamMask {
+	required := rs.requiredMaskForURL(urlPath)
+	if masks.variant != nil {
+		required = required.Union(masks.variant.requiredMask)
+	}
+	return required
+}
+
+// emptyQueryAllowed checks if URL path accepts query without parameters
+func (rs *ruleSnapshot) emptyQueryAllowed(urlPath string) bool {
+	return rs.requiredMaskForURL(urlPath).IsEmpty() && rs.clausesSatisfied(&queryTracker{}, urlPath)
+}
+
+// segmentIndex returns parameter index of query segment key or -1
+func (rs *ruleSnapshot) segmentIndex(segment string, active ParamMask) int {
+	key, _ := splitQuerySegment(segment)
+	if rs.decodingMode != DecodeNone {
+		var ok bool
+		if key, _, ok = rs.decodeKeyValue(key, ""); !ok {
+			return -1
+		}
+	}
+	return rs.lookupParamIndex(key, active)
+}
+
+// segmentIndexBytes returns parameter index of query segment key in []byte form or -1
+func (rs *ruleSnapshot) segmentIndexBytes(segment []byte, active ParamMask, scratch []byte) int {
+	keyBytes := segment
+	for i := 0; i < len(segment); i++ {
+		if segment[i] == '=' {
+			keyBytes = segment[:i]
+			break
+		}
+	}
+	if rs.decodingMode != DecodeNone {
+		var ok bool
+		if keyBytes, _, ok = rs.decodeKeyValueBytes(keyBytes, nil, scratch); !ok {
+			return -1
+		}
+	}
+	return rs.lookupParamIndexBytes(keyBytes, active)
+}
+
+// collectMissingRequired records violation for every required parameter absent from query
+func (rs *ruleSnapshot) collectMissingRequired(result *ValidationResult, present ParamMask, masks ParamMasks, urlPath string) {
+	missing := rs.requiredMaskWithVariant(masks, urlPath).Difference(present)
+	if missing.IsEmpty() {
+		return
+	}
+
+	mostSpecificRule := rs.findMostSpecificURLRuleUnsafe(urlPath)
+	for _, idx := range missing.GetIndices() {
+		violation := Violation{
+			Param:  rs.compiledRules.paramIndex.GetParamName(idx),
+			Kind:   ViolationMissingRequired,
+			Source: SourceGlobal,
+		}
+		if masks.variant != nil && masks.variant.requiredMask.GetBit(idx) {
+			violation.Source = SourceSpecificURL
+			violation.URLPattern = masks.variant.URLPattern
+		} else if mostSpecificRule != nil && mostSpecificRule.requiredMask.GetBit(idx) {
+			violation.Source = SourceSpecificURL
+			violation.URLPattern = mostSpecificRule.URLPattern
+		}
+		result.addViolation(violation)
+	}
+}
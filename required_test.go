@@ -0,0 +1,168 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestRequiredParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		url      string
+		expected bool
+	}{
+		{
+			name:     "required present",
+			rules:    "/search?+q=[len:1..100]&page=[range:1..10]",
+			url:      "/search?q=shoes",
+			expected: true,
+		},
+		{
+			name:     "required missing",
+			rules:    "/search?+q=[len:1..100]&page=[range:1..10]",
+			url:      "/search?page=2",
+			expected: false,
+		},
+		{
+			name:     "required missing with empty query",
+			rules:    "/search?+q=[len:1..100]",
+			url:      "/search",
+			expected: false,
+		},
+		{
+			name:     "required with invalid value",
+			rules:    "/search?+q=[len:1..3]",
+			url:      "/search?q=toolong",
+			expected: false,
+		},
+		{
+			name:     "required key without constraint",
+			rules:    "/search?+q",
+			url:      "/search?q=anything",
+			expected: true,
+		},
+		{
+			name:     "required global missing",
+			rules:    "+token=[*]",
+			url:      "/any?page=1",
+			expected: false,
+		},
+		{
+			name:     "required global missing with empty query",
+			rules:    "+token=[*]",
+			url:      "/any",
+			expected: false,
+		},
+		{
+			name:     "required global redefined by specific rule",
+			rules:    "+token=[*];/public?token=[*]",
+			url:      "/public",
+			expected: true,
+		},
+		{
+			name:     "required global with allow all rule",
+			rules:    "+token=[*];/open?*",
+			url:      "/open?foo=bar",
+			expected: false,
+		},
+		{
+			name:     "required only on most specific rule",
+			rules:    "/api/*?+key=[*];/api/public?page=[*]",
+			url:      "/api/public?page=1",
+			expected: true,
+		},
+		{
+			name:     "no required for other path",
+			rules:    "/search?+q=[*]",
+			url:      "/other",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, tt.rules, WithPlugins(plugins.NewLengthPlugin(), plugins.NewRangePlugin()))
+
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			path, query := tt.url, ""
+			for i := 0; i < len(tt.url); i++ {
+				if tt.url[i] == '?' {
+					path, query = tt.url[:i], tt.url[i+1:]
+					break
+				}
+			}
+			if result := pv.ValidateQuery(path, query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q, %q) = %v, expected %v", path, query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte(path), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q, %q) = %v, expected %v", path, query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequiredParamsFilter(t *testing.T) {
+	pv := newTestValidator(t, "/search?+q=[a,b]&page=[1,2]")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?q=a&page=1&x=1", "/search?q=a&page=1"},
+		{"/search?q=c&page=1", ""},
+		{"/search?page=1", ""},
+		{"/search", ""},
+		{"/other?x=1", "/other"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	if result := pv.FilterQuery("/search", "page=1"); result != "" {
+		t.Errorf("FilterQuery without required = %q, expected empty", result)
+	}
+
+	buffer := make([]byte, 0, 64)
+	if result := pv.FilterQueryBytes([]byte("/search"), []byte("page=1"), buffer); result != nil {
+		t.Errorf("FilterQueryBytes without required = %q, expected nil", result)
+	}
+	if result := pv.FilterQueryBytes([]byte("/search"), []byte("q=b&page=3"), buffer); string(result) != "q=b" {
+		t.Errorf("FilterQueryBytes = %q, expected %q", result, "q=b")
+	}
+}
+
+func TestRequiredParamsDetailed(t *testing.T) {
+	pv := newTestValidator(t, "+token=[*];/search?+q=[*]&page=[1,2]")
+
+	result := pv.ValidateURLDetailed("/search?page=1")
+	if result.Valid {
+		t.Fatal("Expected URL to be invalid")
+	}
+
+	missing := map[string]Violation{}
+	for _, violation := range result.Violations {
+		if violation.Kind != ViolationMissingRequired {
+			t.Errorf("Unexpected violation %+v", violation)
+		}
+		missing[violation.Param] = violation
+	}
+
+	if v, ok := missing["q"]; !ok || v.Source != SourceSpecificURL || v.URLPattern != "/search" {
+		t.Errorf("Expected missing q from /search, got %+v", v)
+	}
+	if v, ok := missing["token"]; !ok || v.Source != SourceGlobal {
+		t.Errorf("Expected missing global token, got %+v", v)
+	}
+}
@@ -0,0 +1,274 @@
+// result.go
+package paramvalidator
+
+import (
+	"net/url"
+)
+
+// String returns human readable name of violation kind
+func (vk ViolationKind) String() string {
+	switch vk {
+	case ViolationNone:
+		return "none"
+	case ViolationInvalidURL:
+		return "invalid url"
+	case ViolationUnknownParam:
+		return "unknown param"
+	case ViolationEnumMismatch:
+		return "enum mismatch"
+	case ViolationPluginRejected:
+		return "plugin rejected"
+	case ViolationCallbackRejected:
+		return "callback rejected"
+	case ViolationKeyOnlyValue:
+		return "key-only given a value"
+	case ViolationInvertedMatch:
+		return "inverted match"
+	case ViolationLimitExceeded:
+		return "limit exceeded"
+	case ViolationInvalidEncoding:
+		return "invalid encoding"
+	case ViolationMissingRequired:
+		return "missing required"
+	case ViolationOccurrences:
+		return "occurrence count"
+	case ViolationDuplicate:
+		return "duplicate param"
+	case ViolationDependency:
+		return "missing dependency"
+	case ViolationGroupConflict:
+		return "group conflict"
+	case ViolationGroupMissing:
+		return "missing group member"
+	case ViolationAssertion:
+		return "assertion failed"
+	case ViolationCondition:
+		return "condition mismatch"
+	default:
+		return "unknown"
+	}
+}
+
+// addViolation records violation and marks result invalid
+func (vr *ValidationResult) addViolation(violation Violation) {
+	vr.Valid = false
+	vr.Violations = append(vr.Violations, violation)
+}
+
+// newInvalidResult creates result rejected with single violation
+func newInvalidResult(kind ViolationKind, value string) ValidationResult {
+	result := ValidationResult{}
+	result.addViolation(Violation{Value: value, Kind: kind})
+	return result
+}
+
+// ValidateURLDetailed validates complete URL and reports every violation
+// The verdict in Valid is always enforced, so it matches ValidateURL unless report-only mode accepts URL
+func (pv *ParamValidator) ValidateURLDetailed(fullURL string) ValidationResult {
+	if !pv.initialized.Load() {
+		return newInvalidResult(ViolationInvalidURL, fullURL)
+	}
+	return pv.current().validateURLDetailed(fullURL)
+}
+
+// validateURLDetailed validates complete URL with rules of snapshot and reports every violation
+func (rs *ruleSnapshot) validateURLDetailed(fullURL string) ValidationResult {
+	if fullURL == "" {
+		return newInvalidResult(ViolationInvalidURL, fullURL)
+	}
+
+	if len(fullURL) > MaxURLLength {
+		return newInvalidResult(ViolationLimitExceeded, fullURL)
+	}
+
+	u, err := url.Parse(fullURL)
+	if err != nil {
+		return newInvalidResult(ViolationInvalidURL, fullURL)
+	}
+
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return newInvalidResult(ViolationInvalidURL, fullURL)
+	}
+
+	result := ValidationResult{Valid: true}
+	masks := rs.getParamMasksForURL(u.Path)
+	rs.selectVariant(&masks, u.Path, u.RawQuery)
+	rs.collectQueryViolations(&result, u.RawQuery, masks, u.Path)
+	return result
+}
+
+// ValidateQueryDetailed validates query parameters for URL path and reports every violation
+// The verdict in Valid is always enforced, so it matches ValidateQuery unless report-only mode accepts query
+func (pv *ParamValidator) ValidateQueryDetailed(urlPath, queryString string) ValidationResult {
+	if !pv.initialized.Load() {
+		return newInvalidResult(ViolationInvalidURL, urlPath)
+	}
+	return pv.current().validateQueryDetailed(urlPath, queryString)
+}
+
+// validateQueryDetailed validates query with rules of snapshot and reports every violation
+func (rs *ruleSnapshot) validateQueryDetailed(urlPath, queryString string) ValidationResult {
+	if urlPath == "" {
+		return newInvalidResult(ViolationInvalidURL, urlPath)
+	}
+
+	if len(urlPath) > MaxURLLength {
+		return newInvalidResult(ViolationLimitExceeded, urlPath)
+	}
+
+	if len(queryString) > MaxURLLength {
+		return newInvalidResult(ViolationLimitExceeded, queryString)
+	}
+
+	result := ValidationResult{Valid: true}
+	masks := rs.createParamMasks(urlPath)
+	rs.selectVariant(&masks, urlPath, queryString)
+	rs.collectQueryViolations(&result, queryString, masks, urlPath)
+	return result
+}
+
+// collectQueryViolations validates every query segment and records violations
+func (rs *ruleSnapshot) collectQueryViolations(result *ValidationResult, queryString string, masks ParamMasks, urlPath string) {
+	if queryString == "" {
+		rs.collectMissingRequired(result, NewParamMask(), masks, urlPath)
+		rs.collectClauseViolations(result, NewParamMask(), &queryTracker{}, urlPath)
+		return
+	}
+
+	rulesLoaded := rs.compiledRules != nil && rs.compiledRules.paramIndex != nil
+	if rulesLoaded && rs.isAllowAllParamsMasks(masks) {
+		var tracker queryTracker
+		if rs.compiledRules.needsTrackerState {
+			tracker.state = &trackerState{}
+		}
+		rs.trackQueryPresence(&tracker, queryString, masks.CombinedMask())
+		rs.collectMissingRequired(result, tracker.present, masks, urlPath)
+		rs.collectClauseViolations(result, tracker.present, &tracker, urlPath)
+		return
+	}
+
+	unknownPattern := ""
+	if mostSpecificRule := rs.findMostSpecificURLRuleUnsafe(urlPath); mostSpecificRule != nil {
+		unknownPattern = mostSpecificRule.URLPattern
+	}
+
+	present := NewParamMask()
+	duplicates := NewParamMask()
+	var tracker queryTracker
+	if rulesLoaded {
+		if rs.compiledRules.needsTrackerState {
+			tracker.state = &trackerState{}
+		}
+		rs.initQueryTracker(&tracker, queryString, masks)
+	}
+	start := 0
+	paramCount := 0
+
+	for i := 0; i <= len(queryString); i++ {
+		if i == len(queryString) || queryString[i] == '&' {
+			if start < i && paramCount < MaxParamValues {
+				segment := queryString[start:i]
+				key, value := splitQuerySegment(segment)
+				violation := Violation{Param: key, Value: value, Kind: ViolationUnknownParam, URLPattern: unknownPattern}
+				if rulesLoaded {
+					check := rs.checkParamDetailed(&violation, masks, urlPath)
+					rs.collectDeprecated(result, key, value, check, masks, urlPath)
+					present.SetBit(check.index)
+					switch admission, kind := rs.admitSegment(&tracker, check, urlPath, start, i); {
+					case admission == admitIgnore:
+						violation.Kind = ViolationNone
+					case kind == ViolationDuplicate:
+						// Repeated parameter is reported once
+						violation.Kind = ViolationNone
+						if !duplicates.GetBit(check.index) {
+							duplicates.SetBit(check.index)
+							violation.Kind = kind
+						}
+					case kind == ViolationCondition:
+						violation.Kind = kind
+						violation.Clause = rs.failedCondition(&tracker, check, urlPath, start, i).condition.String()
+					case kind != ViolationNone:
+						violation.Kind = kind
+					default:
+						rs.collectRewrite(result, rs.conditionedRule(&tracker, check, urlPath), key, value)
+					}
+				}
+				if violation.Kind != ViolationNone {
+					result.addViolation(violation)
+				}
+				paramCount++
+			}
+			start = i + 1
+		}
+	}
+
+	// Query without any segments is still rejected when no rules apply
+	if result.Valid && (!rulesLoaded || masks.CombinedMask().IsEmpty()) {
+		result.addViolation(Violation{Kind: ViolationUnknownParam, URLPattern: unknownPattern})
+		return
+	}
+
+	rs.collectMissingRequired(result, present, masks, urlPath)
+	rs.collectClauseViolations(result, present, &tracker, urlPath)
+	if tracker.countOccurrences {
+		rs.collectOccurrenceViolations(result, &tracker.state.occurrences, tracker.present, masks, urlPath)
+	}
+}
+
+// checkParamDetailed resolves rule for violation parameter and fills verdict details
+func (rs *ruleSnapshot) checkParamDetailed(violation *Violation, masks ParamMasks, urlPath string) segmentCheck {
+	key, value, ok := rs.decodeKeyValue(violation.Param, violation.Value)
+	if !ok {
+		violation.Kind = ViolationInvalidEncoding
+		return segmentCheck{index: -1}
+	}
+	return rs.checkDecodedParam(violation, key, value, masks, urlPath)
+}
+
+// checkDecodedParam resolves rule for decoded key and value of violation and fills verdict details
+func (rs *ruleSnapshot) checkDecodedParam(violation *Violation, key, value string, masks ParamMasks, urlPath string) segmentCheck {
+	active := masks.CombinedMask()
+	idx := rs.lookupParamIndex(key, active)
+	if idx == -1 || !active.GetBit(idx) {
+		return segmentCheck{index: idx}
+	}
+
+	rule, urlRule := rs.resolveParamRuleByIndex(idx, masks, urlPath)
+	if rule == nil {
+		return segmentCheck{index: idx}
+	}
+
+	violation.Source = masks.GetRuleSource(idx)
+	violation.URLPattern = ""
+	if urlRule != nil {
+		violation.URLPattern = urlRule.URLPattern
+	}
+	violation.Kind = rs.checkValue(rule, value, true)
+	if violation.Kind != ViolationNone && rs.repair {
+		violation.Repaired, violation.HasRepair = rs.repairValue(rule, value)
+	}
+	return segmentCheck{index: idx, rule: rule, allowed: violation.Kind == ViolationNone}
+}
+
+// validateParamDetailed checks single parameter like ValidateParam and reports its violation
+func (rs *ruleSnapshot) validateParamDetailed(urlPath, paramName, paramValue string) ValidationResult {
+	result := ValidationResult{Valid: true}
+	violation := Violation{Param: paramName, Value: paramValue, Kind: ViolationUnknownParam}
+	if rs.compiledRules != nil {
+		rs.checkDecodedParam(&violation, paramName, paramValue, rs.getParamMasksForURL(urlPath), urlPath)
+	}
+	if violation.Kind != ViolationNone {
+		result.addViolation(violation)
+	}
+	return result
+}
+
+// splitQuerySegment splits query segment into key and value
+func splitQuerySegment(segment string) (string, string) {
+	for i := 0; i < len(segment); i++ {
+		if segment[i] == '=' {
+			return segment[:i], segment[i+1:]
+		}
+	}
+	return segment, ""
+}
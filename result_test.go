@@ -0,0 +1,207 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestValidateURLDetailed(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      string
+		url        string
+		wantKinds  []ViolationKind
+		wantParams []string
+	}{
+		{
+			name:  "valid url",
+			rules: "/api?page=[5]&sort=[name,date]",
+			url:   "/api?page=5&sort=name",
+		},
+		{
+			name:       "unknown parameter",
+			rules:      "/api?page=[5]",
+			url:        "/api?page=5&debug=1",
+			wantKinds:  []ViolationKind{ViolationUnknownParam},
+			wantParams: []string{"debug"},
+		},
+		{
+			name:       "enum mismatch",
+			rules:      "/api?sort=[name,date]",
+			url:        "/api?sort=price",
+			wantKinds:  []ViolationKind{ViolationEnumMismatch},
+			wantParams: []string{"sort"},
+		},
+		{
+			name:       "plugin rejected",
+			rules:      "/api?page=[range:1..10]",
+			url:        "/api?page=50",
+			wantKinds:  []ViolationKind{ViolationPluginRejected},
+			wantParams: []string{"page"},
+		},
+		{
+			name:       "key-only given a value",
+			rules:      "/api?active=[]",
+			url:        "/api?active=1",
+			wantKinds:  []ViolationKind{ViolationKeyOnlyValue},
+			wantParams: []string{"active"},
+		},
+		{
+			name:       "inverted match",
+			rules:      "/api?status=![deleted]",
+			url:        "/api?status=deleted",
+			wantKinds:  []ViolationKind{ViolationInvertedMatch},
+			wantParams: []string{"status"},
+		},
+		{
+			name:       "callback without function",
+			rules:      "/api?token=[?]",
+			url:        "/api?token=abc",
+			wantKinds:  []ViolationKind{ViolationCallbackRejected},
+			wantParams: []string{"token"},
+		},
+		{
+			name:       "all violations collected",
+			rules:      "/api?page=[5]&sort=[name,date]",
+			url:        "/api?page=6&sort=price&extra=1",
+			wantKinds:  []ViolationKind{ViolationEnumMismatch, ViolationEnumMismatch, ViolationUnknownParam},
+			wantParams: []string{"page", "sort", "extra"},
+		},
+		{
+			name:      "invalid scheme",
+			rules:     "/api?page=[5]",
+			url:       "ftp://example.com/api?page=5",
+			wantKinds: []ViolationKind{ViolationInvalidURL},
+		},
+		{
+			name:      "url too long",
+			rules:     "/api?page=[5]",
+			url:       "/api?page=" + strings.Repeat("5", MaxURLLength),
+			wantKinds: []ViolationKind{ViolationLimitExceeded},
+		},
+		{
+			name:       "no rules for path",
+			rules:      "/api?page=[5]",
+			url:        "/other?page=5",
+			wantKinds:  []ViolationKind{ViolationUnknownParam},
+			wantParams: []string{"page"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := newTestValidator(t, tt.rules, WithPlugins(plugins.NewRangePlugin()))
+
+			result := pv.ValidateURLDetailed(tt.url)
+			if result.Valid != pv.ValidateURL(tt.url) {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, differs from ValidateURL", tt.url, result.Valid)
+			}
+			if result.Valid != (len(tt.wantKinds) == 0) {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, len(tt.wantKinds) == 0)
+			}
+			if len(result.Violations) != len(tt.wantKinds) {
+				t.Fatalf("ValidateURLDetailed(%q) got %d violations %+v, expected %d",
+					tt.url, len(result.Violations), result.Violations, len(tt.wantKinds))
+			}
+			for i, violation := range result.Violations {
+				if violation.Kind != tt.wantKinds[i] {
+					t.Errorf("violation %d kind = %v, expected %v", i, violation.Kind, tt.wantKinds[i])
+				}
+				if i < len(tt.wantParams) && violation.Param != tt.wantParams[i] {
+					t.Errorf("violation %d param = %q, expected %q", i, violation.Param, tt.wantParams[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateURLDetailedSource(t *testing.T) {
+	rules := "page=[1,2,3];/api/*?sort=[name];/api/users?limit=[10]"
+	pv := newTestValidator(t, rules)
+
+	result := pv.ValidateURLDetailed("/api/users?page=9&sort=date&limit=20")
+	if result.Valid {
+		t.Fatal("Expected URL to be invalid")
+	}
+
+	expected := map[string]struct {
+		source  RuleSource
+		pattern string
+	}{
+		"page":  {SourceGlobal, ""},
+		"sort":  {SourceURL, "/api/*"},
+		"limit": {SourceSpecificURL, "/api/users"},
+	}
+
+	if len(result.Violations) != len(expected) {
+		t.Fatalf("Got %d violations %+v, expected %d", len(result.Violations), result.Violations, len(expected))
+	}
+	for _, violation := range result.Violations {
+		want, ok := expected[violation.Param]
+		if !ok {
+			t.Errorf("Unexpected violation for %q", violation.Param)
+			continue
+		}
+		if violation.Source != want.source || violation.URLPattern != want.pattern {
+			t.Errorf("Violation for %q: source %v pattern %q, expected %v %q",
+				violation.Param, violation.Source, violation.URLPattern, want.source, want.pattern)
+		}
+	}
+}
+
+func TestValidateQueryDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/api?page=[5]&limit=[10]")
+
+	queries := []struct {
+		urlPath string
+		query   string
+	}{
+		{"/api", "page=5&limit=10"},
+		{"/api", "page=5&limit=15"},
+		{"/api", ""},
+		{"/users", "page=5"},
+		{"/api", "page=5&" + strings.Repeat("limit=10&", MaxParamValues)},
+	}
+
+	for _, q := range queries {
+		result := pv.ValidateQueryDetailed(q.urlPath, q.query)
+		if result.Valid != pv.ValidateQuery(q.urlPath, q.query) {
+			t.Errorf("ValidateQueryDetailed(%q, %q).Valid = %v, differs from ValidateQuery",
+				q.urlPath, q.query, result.Valid)
+		}
+		if result.Valid != (len(result.Violations) == 0) {
+			t.Errorf("ValidateQueryDetailed(%q, %q) inconsistent result %+v", q.urlPath, q.query, result)
+		}
+	}
+}
+
+func TestParamLimitBoundary(t *testing.T) {
+	pv := newTestValidator(t, "/api?n=[*]")
+
+	tests := []struct {
+		name     string
+		segments int
+		expected bool
+	}{
+		{"unknown param at limit", MaxParamValues, false},
+		{"unknown param past limit", MaxParamValues + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Unknown param is last segment, anything past MaxParamValues is not checked
+			query := strings.Repeat("n=1&", tt.segments-1) + "x=1"
+			if result := pv.ValidateQuery("/api", query); result != tt.expected {
+				t.Errorf("ValidateQuery() = %v, expected %v", result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/api"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes() = %v, expected %v", result, tt.expected)
+			}
+			if result := pv.ValidateQueryDetailed("/api", query); result.Valid != tt.expected {
+				t.Errorf("ValidateQueryDetailed().Valid = %v, expected %v", result.Valid, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// snapshot.go
+package paramvalidator
+
+// ruleSnapshot holds rules compiled by one update and callback checking them
+// Snapshot is never modified after publishing, so calls read it without locking
+// and keep using the snapshot they started with
+type ruleSnapshot struct {
+	*validatorConfig
+	globalParams  map[string]*ParamRule
+	urlRules      map[string]*URLRule
+	globalClauses RuleClauses
+	urlMatcher    *URLMatcher
+	compiledRules *CompiledRules
+	paramIndex    *ParamIndex
+	callbackFunc  CallbackFunc
+	rules         string
+}
+
+// current returns snapshot published by last rule update
+func (pv *ParamValidator) current() *ruleSnapshot {
+	return pv.active.Load()
+}
+
+// newRuleSnapshot creates snapshot without rules
+func newRuleSnapshot(config *validatorConfig, callback CallbackFunc) *ruleSnapshot {
+	return &ruleSnapshot{
+		validatorConfig: config,
+		globalParams:    make(map[string]*ParamRule),
+		urlRules:        make(map[string]*URLRule),
+		urlMatcher:      NewURLMatcher(),
+		paramIndex:      NewParamIndex(),
+		callbackFunc:    callback,
+	}
+}
+
+// buildSnapshotUnsafe compiles rules into new snapshot replacing validators cached for previous rules
+func (pv *ParamValidator) buildSnapshotUnsafe(rulesStr string) (*ruleSnapshot, error) {
+	if pv.parser != nil {
+		pv.parser.ClearCache()
+	}
+	return pv.current().compile(pv.parser, rulesStr)
+}
+
+// compile compiles rules with parser into new snapshot keeping configuration and callback of rs
+// Empty rules build snapshot without rules
+func (rs *ruleSnapshot) compile(parser *RuleParser, rulesStr string) (*ruleSnapshot, error) {
+	next := newRuleSnapshot(rs.validatorConfig, rs.callbackFunc)
+	if rulesStr == "" {
+		return next, nil
+	}
+	if err := next.parseRulesUnsafe(parser, rulesStr); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// withCallback returns copy of snapshot checking callback rules with callback
+func (rs *ruleSnapshot) withCallback(callback CallbackFunc) *ruleSnapshot {
+	next := *rs
+	next.callbackFunc = callback
+	return &next
+}
+
+// withoutObservers returns copy of snapshot that notifies neither warning nor report observer
+func (rs *ruleSnapshot) withoutObservers() *ruleSnapshot {
+	config := *rs.validatorConfig
+	config.warningObserver = nil
+	config.reportObserver = nil
+	next := *rs
+	next.validatorConfig = &config
+	return &next
+}
+
+// publishUnsafe makes snapshot current and records its rules in history
+func (pv *ParamValidator) publishUnsafe(next *ruleSnapshot, info RuleInfo) {
+	pv.active.Store(next)
+	pv.recordRulesUnsafe(next.rules, info)
+}
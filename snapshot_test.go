@@ -0,0 +1,193 @@
+package paramvalidator
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotInFlightCallKeepsRules(t *testing.T) {
+	var pv *ParamValidator
+	reloaded := false
+	pv = newTestValidator(t, "/api?token=[?]&page=[1]", WithCallback(func(string, string) bool {
+		// Updating rules from inside validation must not block or affect in-flight call
+		if !reloaded {
+			reloaded = true
+			if err := pv.ParseRules("/api?token=[?]"); err != nil {
+				t.Errorf("ParseRules failed: %v", err)
+			}
+		}
+		return true
+	}))
+
+	if !pv.ValidateURL("/api?token=a&page=1") {
+		t.Error("Expected in-flight call to keep rules it started with")
+	}
+	if pv.ValidateURL("/api?token=a&page=1") {
+		t.Error("Expected next call to use reloaded rules")
+	}
+}
+
+func TestSnapshotReportUsesEnforcedRules(t *testing.T) {
+	var pv *ParamValidator
+	var reports []Report
+	reloaded := false
+	pv = newTestValidator(t, "/api?token=[?]", WithCallback(func(string, string) bool {
+		// Rules reloaded after enforced verdict must not be used to report it
+		if !reloaded {
+			reloaded = true
+			if err := pv.ParseRules("/api?token=[?]&debug=[1]"); err != nil {
+				t.Errorf("ParseRules failed: %v", err)
+			}
+		}
+		return true
+	}), WithReportOnly(func(report Report) {
+		reports = append(reports, report)
+	}))
+
+	if !pv.ValidateURL("/api?token=a&debug=1") {
+		t.Fatal("Expected report-only mode to accept URL")
+	}
+	if len(reports) != 1 || reports[0].Result.Valid || len(reports[0].Result.Violations) != 1 {
+		t.Fatalf("Reports = %+v, expected violation found by rules call started with", reports)
+	}
+	if violation := reports[0].Result.Violations[0]; violation.Param != "debug" || violation.Kind != ViolationUnknownParam {
+		t.Errorf("Violation = %+v, expected unknown debug", violation)
+	}
+}
+
+func TestSnapshotSetCallbackKeepsRules(t *testing.T) {
+	pv := newTestValidator(t, "/api?token=[?]&page=[1]")
+
+	pv.SetCallback(func(_, value string) bool { return value == "ok" })
+	if !pv.ValidateURL("/api?token=ok&page=1") || pv.ValidateURL("/api?token=bad") {
+		t.Error("Expected new callback to apply to loaded rules")
+	}
+	if rules, _ := pv.RulesString(); rules != "/api?token=[?]&page=[1]" {
+		t.Errorf("RulesString = %q after SetCallback", rules)
+	}
+}
+
+func TestSnapshotConcurrentReload(t *testing.T) {
+	rules := []string{
+		"/api?page=[1,2]&sort=[asc]",
+		"/api?page=[1,2]&sort=[desc]",
+	}
+	pv := newTestValidator(t, rules[0])
+
+	var wg sync.WaitGroup
+	errorCh := make(chan error, 64)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			buffer := make([]byte, 0, 64)
+			for j := 0; j < 200; j++ {
+				// Parameter present in every rule set is accepted whatever snapshot is current
+				if !pv.ValidateURL("/api?page=1") || pv.FilterQuery("/api", "page=2&debug=1") != "page=2" ||
+					string(pv.FilterQueryBytes([]byte("/api"), []byte("page=1"), buffer)) != "page=1" {
+					errorCh <- fmt.Errorf("goroutine %d: verdict changed during reload", id)
+					return
+				}
+				pv.ValidateURLDetailed("/api?sort=asc")
+			}
+		}(i)
+	}
+	for i := 0; i < 100; i++ {
+		if err := pv.ParseRules(rules[i%2]); err != nil {
+			t.Fatalf("ParseRules failed: %v", err)
+		}
+	}
+	wg.Wait()
+	close(errorCh)
+
+	for err := range errorCh {
+		t.Error(err)
+	}
+}
+
+func TestParamIndexConcurrentClear(t *testing.T) {
+	pi := NewParamIndex()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			pi.GetOrCreateIndex(fmt.Sprintf("p%d", i%10))
+			pi.GetIndex("p1")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			pi.Clear()
+		}
+	}()
+	wg.Wait()
+}
+
+func TestValidationCacheConcurrentClear(t *testing.T) {
+	cache := NewValidationCache()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			cache.Put("plugin", "param", fmt.Sprintf("c%d", i%10), func(string) bool { return true })
+			cache.Get("plugin", "param", "c1")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			cache.Clear()
+		}
+	}()
+	wg.Wait()
+}
+
+func BenchmarkSnapshotConcurrentReload(b *testing.B) {
+	rules := []string{
+		"/api/*?page=[5]&limit=[10]&sort=[name,date]",
+		"/api/*?page=[5]&limit=[10]&sort=[name,date,size]",
+	}
+
+	for _, reload := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Reload=%v", reload), func(b *testing.B) {
+			pv := newTestValidator(b, rules[0])
+
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			if reload {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ticker := time.NewTicker(time.Millisecond)
+					defer ticker.Stop()
+					for i := 0; ; i++ {
+						select {
+						case <-done:
+							return
+						case <-ticker.C:
+						}
+						if err := pv.ParseRules(rules[i%2]); err != nil {
+							b.Errorf("ParseRules failed: %v", err)
+							return
+						}
+					}
+				}()
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					pv.ValidateQuery("/api/users", "page=5&limit=10&sort=name")
+				}
+			})
+			b.StopTimer()
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// transform.go
+package paramvalidator
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValueTransform is preprocessing step applied to parameter value before validation
+type ValueTransform int
+
+const (
+	// TransformTrim removes leading and trailing whitespace
+	TransformTrim ValueTransform = iota
+	// TransformLower converts value to lower case
+	TransformLower
+	// TransformUpper converts value to upper case
+	TransformUpper
+)
+
+// String returns rule syntax name of transform
+func (vt ValueTransform) String() string {
+	switch vt {
+	case TransformTrim:
+		return "trim"
+	case TransformLower:
+		return "lower"
+	case TransformUpper:
+		return "upper"
+	default:
+		return "unknown"
+	}
+}
+
+// parseValueTransform converts rule syntax name to transform
+func parseValueTransform(name string) (ValueTransform, bool) {
+	switch name {
+	case "trim":
+		return TransformTrim, true
+	case "lower":
+		return TransformLower, true
+	case "upper":
+		return TransformUpper, true
+	default:
+		return 0, false
+	}
+}
+
+// parseTransforms splits leading transform steps such as "lower|trim|" off constraint
+// Splitting stops at the first part that is not a transform name, so '|' inside constraints is kept
+func parseTransforms(constraintStr string) ([]ValueTransform, string) {
+	var transforms []ValueTransform
+	for {
+		sep := strings.IndexByte(constraintStr, '|')
+		if sep == -1 {
+			return transforms, constraintStr
+		}
+		transform, ok := parseValueTransform(strings.TrimSpace(constraintStr[:sep]))
+		if !ok {
+			return transforms, constraintStr
+		}
+		transforms = append(transforms, transform)
+		constraintStr = strings.TrimSpace(constraintStr[sep+1:])
+	}
+}
+
+// constraintString returns constraint of rule in rule syntax including its transforms and mappings
+func (rule *ParamRule) constraintString() string {
+	var builder strings.Builder
+	for _, transform := range rule.Transforms {
+		builder.WriteString(transform.String())
+		builder.WriteByte('|')
+	}
+	builder.WriteString(rule.ConstraintStr)
+	for i, mapping := range rule.Mappings {
+		if i == 0 {
+			builder.WriteString("; ")
+		} else {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(mapping.From + "->" + mapping.To)
+	}
+	return builder.String()
+}
+
+// transformValue returns value with transforms of rule applied, allocating only when value changes
+func (rule *ParamRule) transformValue(value string) string {
+	if len(rule.Transforms) == 0 {
+		return value
+	}
+
+	var buf [128]byte
+	transformed := appendTransformed(buf[:0], value, rule.Transforms)
+	if string(transformed) == value {
+		return value
+	}
+	return string(transformed)
+}
+
+// transformValues returns values with transforms applied
+func transformValues(values []string, transforms []ValueTransform) []string {
+	if len(transforms) == 0 {
+		return values
+	}
+	rule := ParamRule{Transforms: transforms}
+	transformed := make([]string, len(values))
+	for i, value := range values {
+		transformed[i] = rule.transformValue(value)
+	}
+	return transformed
+}
+
+// transformBytes returns value with transforms applied, built in buf when there are any
+func transformBytes(buf, value []byte, transforms []ValueTransform) []byte {
+	if len(transforms) == 0 {
+		return value
+	}
+	return appendTransformed(buf, value, transforms)
+}
+
+// declaredRule returns rule of parameter declared in params or globally, nil if none
+func (rs *ruleSnapshot) declaredRule(name string, params map[string]*ParamRule) *ParamRule {
+	if rule, exists := params[name]; exists {
+		return rule
+	}
+	if rule, exists := rs.compiledRules.globalParams[name]; exists {
+		return rule
+	}
+	return nil
+}
+
+// declaredTransforms returns transforms of parameter declared in params or globally
+func (rs *ruleSnapshot) declaredTransforms(name string, params map[string]*ParamRule) []ValueTransform {
+	if rule := rs.declaredRule(name, params); rule != nil {
+		return rule.Transforms
+	}
+	return nil
+}
+
+// appendTransformed appends value to dst and applies transforms to the appended part in order
+// Invalid UTF-8 bytes are kept as is
+func appendTransformed[T ~string | ~[]byte](dst []byte, value T, transforms []ValueTransform) []byte {
+	start := len(dst)
+	dst = append(dst, value...)
+
+	for _, transform := range transforms {
+		switch transform {
+		case TransformTrim:
+			n := copy(dst[start:], bytes.TrimSpace(dst[start:]))
+			dst = dst[:start+n]
+		case TransformLower:
+			dst = mapValueCase(dst, start, unicode.ToLower)
+		case TransformUpper:
+			dst = mapValueCase(dst, start, unicode.ToUpper)
+		}
+	}
+	return dst
+}
+
+// mapValueCase maps runes of dst[start:] with mapping, in place while value is ASCII
+func mapValueCase(dst []byte, start int, mapping func(rune) rune) []byte {
+	ascii := true
+	for i := start; i < len(dst); i++ {
+		if dst[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+		dst[i] = byte(mapping(rune(dst[i])))
+	}
+	if ascii {
+		return dst
+	}
+
+	// Case mapping may change encoded length, so mapped runes are built past the value and moved back
+	end := len(dst)
+	for i := start; i < end; {
+		r, size := utf8.DecodeRune(dst[i:end])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, dst[i])
+		} else {
+			dst = utf8.AppendRune(dst, mapping(r))
+		}
+		i += size
+	}
+	n := copy(dst[start:], dst[end:])
+	return dst[:start+n]
+}
+
+// appendRewrittenSegment appends segment whose key or value filtering writes back changed
+// Accepted values are replaced by their transformed or mapped form, rejected ones by fix-up in repair mode,
+// and with CaseRewriteKeys keys matched by folding take casing declared in rules
+func appendRewrittenSegment[T ~string | ~[]byte](rs *ruleSnapshot, dst []byte, segment T, check segmentCheck) ([]byte, bool) {
+	if !check.allowed {
+		return appendRepairedSegment(rs, dst, segment, check)
+	}
+	if check.rule == nil {
+		return dst, false
+	}
+
+	name := rewrittenKey(rs, segment, check.rule)
+	if len(check.rule.Transforms) > 0 || len(check.rule.Mappings) > 0 {
+		var valueBuf, transformBuf [128]byte
+		value := appendSegmentValue(valueBuf[:0], segment, rs.decodingMode)
+		transformed := appendTransformed(transformBuf[:0], value, check.rule.Transforms)
+		if mapped, ok := mapValue(check.rule.Mappings, transformed, rs.foldsValues(check.rule)); ok {
+			return appendSegmentWithValue(rs, dst, segment, name, mapped), true
+		}
+		if !bytes.Equal(transformed, value) {
+			return appendSegmentWithValue(rs, dst, segment, name, transformed), true
+		}
+	}
+	if name == "" {
+		return dst, false
+	}
+
+	dst, keyEnd := appendSegmentKey(rs, dst, segment, name)
+	return append(dst, segment[keyEnd:]...), true
+}
@@ -0,0 +1,194 @@
+package paramvalidator
+
+import (
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+const transformRules = "/list?sort=[lower|trim|name,date,price]&code=[upper|AB,CD]&page=[trim|range:1..10]&limit=[trim|cmp:<=50]&q=[trim|len:1..5]&file=[lower|in:*.go]&tag=![lower|spam]"
+
+var transformPlugins = WithPlugins(plugins.NewRangePlugin(), plugins.NewComparisonPlugin(), plugins.NewLengthPlugin(), plugins.NewPatternPlugin())
+
+func TestTransformValidate(t *testing.T) {
+	pv := newTestValidator(t, transformRules, transformPlugins, WithDecoding(DecodeQuery))
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"sort=Date", true},
+		{"sort=+date+", true},
+		{"sort=%20PRICE", true},
+		{"sort=dates", false},
+		{"code=ab", true},
+		{"code=ef", false},
+		{"page=+7", true},
+		{"page=+70", false},
+		{"limit=50+", true},
+		{"q=++abcde++", true},
+		{"q=+++", false},
+		{"file=MAIN.GO", true},
+		{"tag=SPAM", false},
+		{"tag=Ham", true},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateQuery("/list", tt.query); result != tt.expected {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateURL("/list?" + tt.query); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateQueryBytes([]byte("/list"), []byte(tt.query)); result != tt.expected {
+			t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", tt.query, result, tt.expected)
+		}
+		if result := pv.ValidateURLDetailed("/list?" + tt.query); result.Valid != tt.expected {
+			t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.query, result.Valid, tt.expected)
+		}
+	}
+}
+
+func TestTransformFilterWritesBack(t *testing.T) {
+	pv := newTestValidator(t, transformRules, transformPlugins)
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/list?sort=Date&code=ab", "/list?sort=date&code=AB"},
+		{"/list?sort=name&page=3", "/list?sort=name&page=3"},
+		{"/list?file=Main.GO&tag=Ham", "/list?file=main.go&tag=ham"},
+		{"/list?sort=Bogus&code=cd", "/list?code=CD"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	decoding := newTestValidator(t, transformRules, transformPlugins, WithDecoding(DecodeQuery))
+	if result := decoding.FilterQuery("/list", "sort=+Date+&q=%20a%20b%20&page=4"); result != "sort=date&q=a%20b&page=4" {
+		t.Errorf("FilterQuery = %q, expected transformed values", result)
+	}
+	// Transformed value is re-encoded, so decoded '&' does not split it
+	if result := decoding.FilterQuery("/list", "q=%20a%26b%20"); result != "q=a%26b" {
+		t.Errorf("FilterQuery = %q, expected reserved character re-encoded", result)
+	}
+
+	buffer := make([]byte, 0, 128)
+	if result := decoding.FilterQueryBytes([]byte("/list"), []byte("code=ab&sort=PRICE"), buffer); string(result) != "code=AB&sort=price" {
+		t.Errorf("FilterQueryBytes = %q, expected transformed values", result)
+	}
+}
+
+func TestTransformUnicode(t *testing.T) {
+	pv := newTestValidator(t, "/list?city=[lower|москва,istanbul,berlin]", WithDecoding(DecodePercent))
+
+	if !pv.ValidateQuery("/list", "city=%D0%9C%D0%BE%D1%81%D0%BA%D0%B2%D0%B0") {
+		t.Error("Expected capitalized cyrillic value to match after lower")
+	}
+	if result := pv.FilterQuery("/list", "city=BERLIN"); result != "city=berlin" {
+		t.Errorf("FilterQuery = %q, expected lower-cased value", result)
+	}
+	// Lower-cased dotted capital I is shorter than the original
+	if result := pv.FilterQuery("/list", "city=%C4%B0STANBUL"); result != "city=istanbul" {
+		t.Errorf("FilterQuery = %q, expected shortened lower-cased value", result)
+	}
+}
+
+func TestTransformClauses(t *testing.T) {
+	pv := newTestValidator(t, "/search?type=[lower|image,video]&q=[*]; variant(type=IMAGE) width=[*]; "+
+		"/catalog?category=[trim|shoes,books]&sub=[*]; when(category=shoes) sub=[lower|sneakers,boots]")
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/search?type=Image&width=10", true},
+		{"/search?type=VIDEO&width=10", false},
+		{"/catalog?category=shoes&sub=Boots", true},
+		{"/catalog?category=%20shoes&sub=hats", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestTransformFilterQueryBytesGrowingValue(t *testing.T) {
+	pv := newTestValidator(t, "/list?name=[upper|*]&a=[*]")
+
+	// Upper-cased turned a takes three bytes instead of two
+	tests := []struct {
+		query    string
+		capacity int
+		expected string
+	}{
+		{"name=ɐ", 8, "name=Ɐ"},
+		{"name=ɐ", 7, ""},
+		{"a=1&name=ɐ", 12, "a=1&name=Ɐ"},
+		{"a=1&name=ɐ", 11, ""},
+	}
+
+	for _, tt := range tests {
+		result := pv.FilterQueryBytes([]byte("/list"), []byte(tt.query), make([]byte, 0, tt.capacity))
+		if string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q, cap %d) = %q, expected %q", tt.query, tt.capacity, result, tt.expected)
+		}
+	}
+}
+
+func TestTransformDuplicates(t *testing.T) {
+	pv := newTestValidator(t, "/list?sort=[lower|name,date]@first&code=[upper|AB,CD]@last&tag=![lower|spam]&q=[*]")
+
+	// Ignored occurrences are value-checked too, filtering keeps transformed occurrence of policy
+	tests := []struct {
+		query    string
+		valid    bool
+		filtered string
+	}{
+		{"sort=NAME&sort=bogus", false, "sort=name"},
+		{"sort=NAME&sort=Date", true, "sort=name"},
+		{"sort=bogus&sort=DATE", false, ""},
+		{"code=ab&code=cd", true, "code=CD"},
+		{"code=cd&code=zz&q=1", false, "q=1"},
+		{"tag=Ham&tag=SPAM", false, "tag=ham"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateQuery("/list", tt.query); result != tt.valid {
+			t.Errorf("ValidateQuery(%q) = %v, expected %v", tt.query, result, tt.valid)
+		}
+		if result := pv.FilterQuery("/list", tt.query); result != tt.filtered {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, result, tt.filtered)
+		}
+	}
+}
+
+func TestTransformRequired(t *testing.T) {
+	pv := newTestValidator(t, "/list?+q=[trim|len:1..5]&code=[upper|AB,CD]", transformPlugins, WithDecoding(DecodeQuery))
+
+	// Value trimmed to nothing fails length check, so required parameter is missing
+	if pv.ValidateQuery("/list", "q=+++&code=ab") {
+		t.Error("Expected blank required value to be rejected")
+	}
+	if result := pv.FilterQuery("/list", "q=+++&code=ab"); result != "" {
+		t.Errorf("FilterQuery = %q, expected empty result without required parameter", result)
+	}
+	if result := pv.FilterQuery("/list", "q=+x+&code=ab"); result != "q=x&code=AB" {
+		t.Errorf("FilterQuery = %q, expected transformed values", result)
+	}
+}
+
+func TestTransformDefaults(t *testing.T) {
+	pv := newTestValidator(t, "/list?sort=[lower|name,date]=DATE&page=[*]")
+
+	// Default is checked and filled after transforms
+	if result := pv.NormalizeURL("/list?page=1"); result != "/list?page=1&sort=date" {
+		t.Errorf("NormalizeURL = %q, expected transformed default", result)
+	}
+}
@@ -38,6 +38,7 @@ const (
 	MaxRulesSize       = 10000
 	MaxPatternLength   = 1024
 	MaxParamsCount     = 128
+	DefaultHistorySize = 16
 )
 
 // RuleSource represents the source of parameter rule
@@ -50,24 +51,134 @@ const (
 	SourceSpecificURL
 )
 
+// ViolationKind describes why a parameter was rejected
+type ViolationKind int
+
+const (
+	ViolationNone ViolationKind = iota
+	ViolationInvalidURL
+	ViolationUnknownParam
+	ViolationEnumMismatch
+	ViolationPluginRejected
+	ViolationCallbackRejected
+	ViolationKeyOnlyValue
+	ViolationInvertedMatch
+	ViolationLimitExceeded
+	ViolationInvalidEncoding
+	ViolationMissingRequired
+	ViolationOccurrences
+	ViolationDuplicate
+	ViolationDependency
+	ViolationGroupConflict
+	ViolationGroupMissing
+	ViolationAssertion
+	ViolationCondition
+)
+
+// Violation describes a single rejected parameter
+type Violation struct {
+	Param      string
+	Value      string
+	Kind       ViolationKind
+	URLPattern string
+	Source     RuleSource
+	Clause     string
+	Repaired   string
+	HasRepair  bool
+}
+
+// ValidationResult contains validation verdict with rejection details
+type ValidationResult struct {
+	Valid      bool
+	Violations []Violation
+	Warnings   []Warning
+	Rewrites   []Rewrite
+}
+
 // ParamRule defines validation rule for single parameter
 type ParamRule struct {
 	Name            string
 	Pattern         string
 	Values          []string
 	CustomValidator func(string) bool
+	Aliases         []string
+	Transforms      []ValueTransform
+	Mappings        []ValueMapping
+	Repair          func(string) (string, bool)
 	BitmaskIndex    int
 	Inverted        bool
+	Required        bool
+	Deprecated      bool
+	MinOccurs       int
+	MaxOccurs       int
+	DuplicatePolicy DuplicatePolicy
+	Default         string
+	HasDefault      bool
+	CaseInsensitive bool
 	ConstraintStr   string
+	position        int
 }
 
 // URLRule defines validation rules for specific URL pattern
 type URLRule struct {
 	URLPattern    string
 	Params        map[string]*ParamRule
+	Clauses       RuleClauses
 	ParamMask     ParamMask
 	specificity   int16
 	paramsByIndex map[int]*ParamRule
+	requiredMask  ParamMask
+	clauses       compiledClauses
+	variants      []compiledVariant
+}
+
+// Dependency requires prerequisite parameters whenever dependent parameter is present
+type Dependency struct {
+	Param    string
+	Requires []string
+}
+
+// GroupKind defines how many alternatives of parameter group may be present together
+type GroupKind int
+
+const (
+	// GroupOneOf requires exactly one alternative
+	GroupOneOf GroupKind = iota
+	// GroupExclusive allows at most one alternative
+	GroupExclusive
+	// GroupAtLeastOne requires one or more alternatives
+	GroupAtLeastOne
+)
+
+// ParamGroup constrains presence of alternatives, each alternative being a set of parameters
+type ParamGroup struct {
+	Kind         GroupKind
+	Alternatives [][]string
+}
+
+// Assertion compares values of two parameters when both are present
+// Operator is one of <, <=, >, >=, == or !=
+type Assertion struct {
+	Left     string
+	Operator string
+	Right    string
+}
+
+// Condition pairs values of discriminator parameter with parameter rules
+// In when clauses Params narrow values of declared parameters, in variant clauses they add parameters to URL rule
+type Condition struct {
+	Param  string
+	Values []string
+	Params map[string]*ParamRule
+}
+
+// RuleClauses contains cross-parameter constraints declared after parameter rules
+type RuleClauses struct {
+	Dependencies []Dependency
+	Groups       []ParamGroup
+	Assertions   []Assertion
+	Conditions   []Condition
+	Variants     []Condition
 }
 
 // ParamMask represents a bitmask for parameter indexing
@@ -80,6 +191,7 @@ type ParamMasks struct {
 	Global      ParamMask // Global parameters (lowest priority)
 	URL         ParamMask // Regular URL rules (medium priority)
 	SpecificURL ParamMask // Specific URL rules (highest priority)
+	variant     *URLRule  // Variant of most specific URL rule selected by query
 }
 
 // CompiledRules contains pre-compiled rules for faster access
@@ -90,27 +202,65 @@ type CompiledRules struct {
 	globalParamsMask    ParamMask
 	globalParamsByIndex map[int]*ParamRule
 	urlRulesByIndex     map[int][]*URLRule
+	globalRequiredMask  ParamMask
+	hasRequired         bool
+	hasOccurrences      bool
+	hasDuplicatePolicy  bool
+	namePatterns        []paramNamePattern
+	foldedNames         []foldedParamName
+	globalClauses       compiledClauses
+	clauseRules         []*URLRule
+	hasClauses          bool
+	hasAssertions       bool
+	discriminatorMask   ParamMask
+	conditionedMask     ParamMask
+	hasConditions       bool
+	hasVariants         bool
+	defaultParams       []int
+	hasDefaults         bool
+	needsTrackerState   bool
 }
 
 // ParamIndex provides lock-free parameter indexing
 type ParamIndex struct {
 	paramToIndex sync.Map // string -> int (lock-free)
+	aliasToIndex sync.Map // alias -> index of primary name
+	hasAliases   atomic.Bool
 	nextIndex    atomic.Int32
 	maxIndex     int32
 }
 
 // ParamValidator main struct for parameter validation
 type ParamValidator struct {
-	globalParams  map[string]*ParamRule
-	urlRules      map[string]*URLRule
-	urlMatcher    *URLMatcher
-	compiledRules *CompiledRules
-	callbackFunc  CallbackFunc
-	initialized   atomic.Bool
-	mu            sync.RWMutex
-	parser        *RuleParser
-	paramIndex    *ParamIndex
-	rules         string
+	validatorConfig
+	callbackFunc CallbackFunc // callback of first snapshot, SetCallback replaces it in snapshots
+	initialized  atomic.Bool
+	active       atomic.Pointer[ruleSnapshot] // rules read without locking
+	mu           sync.RWMutex                 // serializes rule updates
+	historySize  int
+	history      []RuleVersion
+	lastVersion  uint64
+}
+
+// validatorConfig holds options fixed when validator is created
+type validatorConfig struct {
+	parser          *RuleParser
+	decodingMode    DecodingMode
+	duplicatePolicy DuplicatePolicy
+	defaultsMode    DefaultsMode
+	canonicalMode   CanonicalMode
+	repair          bool
+	caseFolding     CaseFolding
+	renameAliases   bool
+	warningObserver WarningObserver
+	reportObserver  ReportObserver
+}
+
+// segmentCheck holds result of single query segment check
+type segmentCheck struct {
+	index   int
+	rule    *ParamRule
+	allowed bool
 }
 
 // wildcardPatternStats contains statistics for URL pattern matching optimization
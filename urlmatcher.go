@@ -63,7 +63,12 @@ func (um *URLMatcher) GetMatchingRules(urlPath string) []*URLRule {
 func (um *URLMatcher) GetMostSpecificRule(urlPath string) *URLRule {
 	um.mu.RLock()
 	defer um.mu.RUnlock()
+	return um.mostSpecificRule(urlPath)
+}
 
+// mostSpecificRule finds the most specific matching rule without locking
+// Validator calls it on matcher of published snapshot that is never modified
+func (um *URLMatcher) mostSpecificRule(urlPath string) *URLRule {
 	var mostSpecificRule *URLRule
 	maxSpecificity := int16(-1)
 
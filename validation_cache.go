@@ -48,7 +48,7 @@ func (vc *ValidationCache) Put(pluginName, paramName, constraint string, validat
 
 // Clear removes all cached validation functions
 func (vc *ValidationCache) Clear() {
-	vc.cache = sync.Map{}
+	vc.cache.Clear()
 }
 
 // Size returns approximate number of cached functions
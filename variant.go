@@ -0,0 +1,160 @@
+// variant.go
+package paramvalidator
+
+// compiledVariant is rule variant selected by discriminator value
+type compiledVariant struct {
+	param   int
+	names   []string
+	values  discriminatorValues
+	foldKey bool
+	rule    *URLRule
+}
+
+// compileVariants builds URL rules holding parameters of each variant
+func (rs *ruleSnapshot) compileVariants(urlRule *URLRule) {
+	for _, variant := range urlRule.Clauses.Variants {
+		variantRule := &URLRule{
+			URLPattern:    urlRule.URLPattern,
+			Params:        make(map[string]*ParamRule),
+			ParamMask:     NewParamMask(),
+			specificity:   urlRule.specificity,
+			paramsByIndex: make(map[int]*ParamRule),
+		}
+
+		for paramName, paramRule := range variant.Params {
+			paramRuleCopy := rs.copyParamRuleUnsafe(paramRule)
+			idx := rs.paramIndex.GetOrCreateIndex(paramName)
+			if idx == -1 {
+				continue
+			}
+
+			paramRuleCopy.BitmaskIndex = idx
+			// Variant parameters are declared after parameters of their rule
+			paramRuleCopy.position += MaxRulesSize
+			variantRule.Params[paramName] = paramRuleCopy
+			variantRule.ParamMask.SetBit(idx)
+			variantRule.paramsByIndex[idx] = paramRuleCopy
+			rs.registerParamName(paramRuleCopy, idx)
+			if paramRuleCopy.Required || paramRuleCopy.MinOccurs > 0 {
+				variantRule.requiredMask.SetBit(idx)
+				rs.compiledRules.hasRequired = true
+			}
+			if paramRuleCopy.MinOccurs > 0 || paramRuleCopy.MaxOccurs > 0 {
+				rs.compiledRules.hasOccurrences = true
+			}
+			if paramRuleCopy.DuplicatePolicy != DuplicateDefault {
+				rs.compiledRules.hasDuplicatePolicy = true
+			}
+			if paramRuleCopy.HasDefault {
+				rs.compiledRules.addDefaultParam(idx)
+			}
+		}
+
+		discriminator := rs.declaredRule(variant.Param, urlRule.Params)
+		names := []string{variant.Param}
+		if discriminator != nil {
+			names = discriminator.paramNames()
+		}
+		urlRule.variants = append(urlRule.variants, compiledVariant{
+			param:   rs.paramIndex.GetIndex(variant.Param),
+			names:   names,
+			values:  rs.newDiscriminatorValues(variant.Values, discriminator),
+			foldKey: rs.foldsKey(discriminator),
+			rule:    variantRule,
+		})
+		rs.compiledRules.hasVariants = true
+	}
+}
+
+// selectVariant activates variant of most specific URL rule matching discriminator value in query
+func (rs *ruleSnapshot) selectVariant(masks *ParamMasks, urlPath, queryString string) {
+	if rs.compiledRules == nil || !rs.compiledRules.hasVariants {
+		return
+	}
+	if urlRule := rs.findMostSpecificURLRuleUnsafe(urlPath); urlRule != nil && len(urlRule.variants) > 0 {
+		masks.applyVariant(findVariant(urlRule, queryString, rs.decodingMode))
+	}
+}
+
+// selectVariantBytes activates variant for query in []byte form without allocations
+func (rs *ruleSnapshot) selectVariantBytes(masks *ParamMasks, urlPath string, queryBytes []byte) {
+	if rs.compiledRules == nil || !rs.compiledRules.hasVariants {
+		return
+	}
+	if urlRule := rs.findMostSpecificURLRuleUnsafe(urlPath); urlRule != nil && len(urlRule.variants) > 0 {
+		masks.applyVariant(findVariant(urlRule, queryBytes, rs.decodingMode))
+	}
+}
+
+// applyVariant adds variant parameters to the most specific layer of masks
+// Ambiguous variant instead removes its discriminator from masks, so validation rejects it
+// and filtering drops it together with parameters of every variant
+func (pm *ParamMasks) applyVariant(variant *compiledVariant, ambiguous bool) {
+	if variant == nil {
+		return
+	}
+	if ambiguous {
+		pm.Global.ClearBit(variant.param)
+		pm.URL.ClearBit(variant.param)
+		pm.SpecificURL.ClearBit(variant.param)
+		return
+	}
+	pm.SpecificURL = pm.SpecificURL.Union(variant.rule.ParamMask)
+	pm.URL = pm.URL.Difference(variant.rule.ParamMask)
+	pm.variant = variant.rule
+}
+
+// findVariant returns first variant whose discriminator holds one of its values
+// Repeated discriminator must select variant in every occurrence, otherwise variant is ambiguous
+// so no occurrence can pair variant parameters with another variant
+func findVariant[T ~string | ~[]byte](urlRule *URLRule, query T, mode DecodingMode) (*compiledVariant, bool) {
+	for i := range urlRule.variants {
+		variant := &urlRule.variants[i]
+		if matched, mismatched := countVariantMatches(variant, query, mode); matched > 0 {
+			return variant, mismatched > 0
+		}
+	}
+	return nil, false
+}
+
+// countVariantMatches counts occurrences of discriminator in query selecting variant and those selecting something else
+func countVariantMatches[T ~string | ~[]byte](variant *compiledVariant, query T, mode DecodingMode) (matched, mismatched int) {
+	start := 0
+	for i := 0; i <= len(query); i++ {
+		if i == len(query) || query[i] == '&' {
+			for _, name := range variant.names {
+				if start == i || !segmentKeyEquals(query[start:i], name, mode, variant.foldKey) {
+					continue
+				}
+				var valueBuf [64]byte
+				if variant.values.matches(appendSegmentValue(valueBuf[:0], query[start:i], mode)) {
+					matched++
+				} else {
+					mismatched++
+				}
+				break
+			}
+			start = i + 1
+		}
+	}
+	return matched, mismatched
+}
+
+// segmentKeyEquals checks if decoded key of query segment equals name, under case folding when fold is set
+func segmentKeyEquals[T ~string | ~[]byte](segment T, name string, mode DecodingMode, fold bool) bool {
+	key := segment
+	for i := 0; i < len(segment); i++ {
+		if segment[i] == '=' {
+			key = segment[:i]
+			break
+		}
+	}
+
+	if !needsDecoding(mode, key) {
+		return fold && equalFold(key, name) || string(key) == name
+	}
+
+	var keyBuf [64]byte
+	decoded, ok := appendDecoded(keyBuf[:0], key, mode)
+	return ok && (fold && equalFold(decoded, name) || string(decoded) == name)
+}
@@ -0,0 +1,155 @@
+package paramvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smalloff/paramvalidator/plugins"
+)
+
+func TestRuleVariants(t *testing.T) {
+	rules := "/search?type=[image,video,text]&q=[*]; " +
+		"variant(type=image) width=[range:1..4000]&height=[range:1..4000]; " +
+		"variant(type=video) +duration=[range:1..600]"
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"image params", "/search?type=image&width=800&height=600", true},
+		{"discriminator after variant params", "/search?width=800&type=image", true},
+		{"video param leaks into image", "/search?type=image&duration=10", false},
+		{"image param leaks into video", "/search?type=video&duration=10&width=800", false},
+		{"required variant param", "/search?type=video&duration=10", true},
+		{"required variant param missing", "/search?type=video&q=cats", false},
+		{"variant value rejected", "/search?type=image&width=0", false},
+		{"no variant for value", "/search?type=text&q=cats", true},
+		{"no variant for value with variant param", "/search?type=text&width=800", false},
+		{"no discriminator", "/search?q=cats", true},
+		{"no discriminator with variant param", "/search?q=cats&width=800", false},
+		{"repeated discriminator agrees", "/search?type=video&duration=10&type=video", true},
+		{"repeated discriminator disagrees", "/search?type=image&width=1&type=video", false},
+		{"repeated discriminator without value", "/search?type&type=video&duration=10", false},
+	}
+
+	pv := newTestValidator(t, rules, WithPlugins(plugins.NewRangePlugin()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := pv.ValidateURL(tt.url); result != tt.expected {
+				t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+			}
+			if result := pv.ValidateURLDetailed(tt.url); result.Valid != tt.expected {
+				t.Errorf("ValidateURLDetailed(%q).Valid = %v, expected %v", tt.url, result.Valid, tt.expected)
+			}
+
+			query := tt.url[strings.Index(tt.url, "?")+1:]
+			if result := pv.ValidateQuery("/search", query); result != tt.expected {
+				t.Errorf("ValidateQuery(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryBytes([]byte("/search"), []byte(query)); result != tt.expected {
+				t.Errorf("ValidateQueryBytes(%q) = %v, expected %v", query, result, tt.expected)
+			}
+			if result := pv.ValidateQueryDetailed("/search", query); result.Valid != tt.expected {
+				t.Errorf("ValidateQueryDetailed(%q).Valid = %v, expected %v", query, result.Valid, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRuleVariantsRepeatedDiscriminator(t *testing.T) {
+	pv := newTestValidator(t, "/search?type=[image,video,text]&q=[*]; "+
+		"variant(type=image) width=[*]; variant(type=video) +duration=[*]")
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"type&type=video", ""},
+		{"type=video&duration=5&type=video", "type=video&duration=5&type=video"},
+		{"type=image&width=1&type=video&q=cats", "q=cats"},
+		{"type=text&type=video&duration=5&q=cats", "q=cats"},
+	}
+
+	for _, tt := range tests {
+		// Discriminator repeated with disagreeing values is dropped together with variant parameters
+		filtered := pv.FilterQuery("/search", tt.query)
+		if filtered != tt.expected {
+			t.Errorf("FilterQuery(%q) = %q, expected %q", tt.query, filtered, tt.expected)
+		}
+		buffer := make([]byte, 0, len(tt.query))
+		if result := pv.FilterQueryBytes([]byte("/search"), []byte(tt.query), buffer); string(result) != tt.expected {
+			t.Errorf("FilterQueryBytes(%q) = %q, expected %q", tt.query, result, tt.expected)
+		}
+		if filtered != "" && !pv.ValidateQuery("/search", filtered) {
+			t.Errorf("Filtered query %q of %q is invalid", filtered, tt.query)
+		}
+	}
+}
+
+func TestRuleVariantsSharedParams(t *testing.T) {
+	pv := newTestValidator(t, "/media?kind=[photo,clip]; variant(kind=photo) size=[s,m,l]; variant(kind=clip) size=[hd,4k]",
+		WithDecoding(DecodeQuery))
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"/media?kind=photo&size=m", true},
+		{"/media?kind=photo&size=4k", false},
+		{"/media?size=4k&kind=clip", true},
+		{"/media?kind=%63lip&size=hd", true},
+		{"/media?kind=clip&size=s", false},
+	}
+
+	for _, tt := range tests {
+		if result := pv.ValidateURL(tt.url); result != tt.expected {
+			t.Errorf("ValidateURL(%q) = %v, expected %v", tt.url, result, tt.expected)
+		}
+	}
+}
+
+func TestRuleVariantsDetailed(t *testing.T) {
+	pv := newTestValidator(t, "/search?type=[image,video]; variant(type=image) width=[*]; variant(type=video) +duration=[*]")
+
+	result := pv.ValidateURLDetailed("/search?type=video&width=800")
+	if len(result.Violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", result.Violations)
+	}
+	if v := result.Violations[0]; v.Kind != ViolationUnknownParam || v.Param != "width" || v.URLPattern != "/search" {
+		t.Errorf("Expected width reported as unknown, got %+v", v)
+	}
+	if v := result.Violations[1]; v.Kind != ViolationMissingRequired || v.Param != "duration" || v.Source != SourceSpecificURL {
+		t.Errorf("Expected missing duration reported, got %+v", v)
+	}
+}
+
+func TestRuleVariantsFilter(t *testing.T) {
+	pv := newTestValidator(t, "/search?type=[image,video]&q=[*]; variant(type=image) width=[*]&height=[*]; variant(type=video) duration=[*]")
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"/search?type=image&width=800&duration=10", "/search?type=image&width=800"},
+		{"/search?duration=10&width=800&type=video", "/search?duration=10&type=video"},
+		{"/search?q=cats&width=800", "/search?q=cats"},
+	}
+
+	for _, tt := range tests {
+		if result := pv.FilterURL(tt.url); result != tt.expected {
+			t.Errorf("FilterURL(%q) = %q, expected %q", tt.url, result, tt.expected)
+		}
+	}
+
+	if result := pv.FilterQuery("/search", "type=video&width=1&duration=2"); result != "type=video&duration=2" {
+		t.Errorf("FilterQuery = %q", result)
+	}
+
+	query := []byte("type=image&height=600&duration=10")
+	buffer := make([]byte, 0, len(query))
+	if result := pv.FilterQueryBytes([]byte("/search"), query, buffer); string(result) != "type=image&height=600" {
+		t.Errorf("FilterQueryBytes = %q", result)
+	}
+}